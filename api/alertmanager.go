@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/events"
+)
+
+// AlertmanagerRequest is the payload of an Alertmanager webhook receiver: https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type AlertmanagerRequest struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+type AlertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+type AlertmanagerResult struct {
+	Job      string `json:"job"`
+	Group    string `json:"group"`
+	Amount   int    `json:"amount"`
+	Eval     string `json:"eval,omitempty"`
+	NewCount int    `json:"new_count,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type alertmanagerTarget struct {
+	job, group string
+}
+
+// get reads a libra_ setting of an alert, labels take precedence over annotations
+func (a AlertmanagerAlert) get(key string) string {
+	if v, ok := a.Labels[key]; ok {
+		return v
+	}
+	return a.Annotations[key]
+}
+
+// amount returns the change in count an alert asks for
+func (a AlertmanagerAlert) amount() (int, error) {
+	amount := 1
+	if s := a.get("libra_amount"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("libra_amount must be a positive integer, got %q", s)
+		}
+		amount = n
+	}
+	return actionAmount(a.get("libra_action"), amount)
+}
+
+// AlertmanagerHandler scales the task groups named by the firing alerts of an
+// Alertmanager notification. Alerts for the same task group are combined: the
+// largest increase wins, otherwise the largest decrease. Resolved alerts are
+// ignored. Failures are reported in the body with a 200 so Alertmanager does
+// not retry the notification and repeat the scaling that succeeded.
+func (rt *Runtime) AlertmanagerHandler(w rest.ResponseWriter, r *rest.Request) {
+	var t AlertmanagerRequest
+	err := r.DecodeJsonPayload(&t)
+	if err != nil {
+		log.Errorln(err)
+		rest.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	log.Infof("Received Alertmanager webhook for %s (%s, %d alerts)", t.GroupKey, t.Status, len(t.Alerts))
+
+	amounts := map[alertmanagerTarget]int{}
+	results := []AlertmanagerResult{}
+	for _, alert := range t.Alerts {
+		if alert.Status != "firing" {
+			continue
+		}
+		target := alertmanagerTarget{job: alert.get("libra_job"), group: alert.get("libra_group")}
+		if target.job == "" || target.group == "" {
+			log.Warnf("Ignoring alert %s without libra_job and libra_group", alert.Fingerprint)
+			continue
+		}
+		amount, err := alert.amount()
+		if err != nil {
+			log.Errorf("Problem with alert %s for %s/%s: %s", alert.Fingerprint, target.job, target.group, err)
+			results = append(results, AlertmanagerResult{Job: target.job, Group: target.group, Error: err.Error()})
+			continue
+		}
+
+		current, ok := amounts[target]
+		switch {
+		case !ok:
+			amounts[target] = amount
+		case amount > 0 && amount > current:
+			amounts[target] = amount
+		case amount < 0 && current < 0 && amount < current:
+			amounts[target] = amount
+		}
+	}
+
+	if len(amounts) == 0 {
+		log.Infof("No firing alerts for Libra in %s. Doing nothing...", t.GroupKey)
+	}
+	for target, amount := range amounts {
+		result := AlertmanagerResult{Job: target.job, Group: target.group, Amount: amount}
+		configGroup, err := rt.Group(target.job, target.group)
+		if err != nil {
+			log.Errorf("Problem scaling from Alertmanager: %s", err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		evalID, newCount, err := rt.scale(events.Event{Source: "alertmanager", Job: target.job, Group: target.group}, amount, configGroup.MinCount, configGroup.MaxCount)
+		if err != nil {
+			log.Errorf("Problem scaling the task group %s/%s: %s", target.job, target.group, err)
+			result.Error = err.Error()
+		} else {
+			log.Infof("Scaled %s/%s by %d to %d! Evaluation %s", target.job, target.group, amount, newCount, evalID)
+			result.Eval = evalID
+			result.NewCount = newCount
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Job != results[j].Job {
+			return results[i].Job < results[j].Job
+		}
+		return results[i].Group < results[j].Group
+	})
+	w.WriteJson(results)
+}
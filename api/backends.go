@@ -2,13 +2,13 @@ package api
 
 import (
 	"net/http"
-
-	"os"
+	"sort"
+	"time"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	log "github.com/sirupsen/logrus"
 	"github.com/underarmour/libra/backend"
-	"github.com/underarmour/libra/config"
+	"github.com/underarmour/libra/structs"
 )
 
 type BackendResponse struct {
@@ -16,33 +16,131 @@ type BackendResponse struct {
 	Kind string `json:"kind"`
 }
 
-func BackendsHandler(w rest.ResponseWriter, r *rest.Request) {
-	config, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-	if err != nil {
-		log.Errorf("Failed to read or parse config file: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Info("Loaded and parsed configuration file")
-	backends, err := backend.InitializeBackends(config.Backends)
-	if err != nil {
-		log.Errorf("Failed to get backends: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+type BackendStatusResponse struct {
+	Name   string         `json:"name"`
+	Kind   string         `json:"kind"`
+	Check  CheckResponse  `json:"check"`
+	Health backend.Health `json:"health"`
+}
+
+type CheckResponse struct {
+	Supported bool    `json:"supported"`
+	OK        bool    `json:"ok"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+type BackendTestRequest struct {
+	Job   string `json:"job"`
+	Group string `json:"group"`
+	Rule  string `json:"rule"`
+}
+
+type BackendTestResponse struct {
+	Backend string  `json:"backend"`
+	Value   float64 `json:"value"`
+	// Datapoints are the series the value was reduced from, for backends that report them
+	Datapoints      []structs.Series `json:"datapoints,omitempty"`
+	Comparison      string           `json:"comparison"`
+	ComparisonValue float64          `json:"comparison_value"`
+	Triggered       bool             `json:"triggered"`
+	Action          string           `json:"action"`
+	ActionValue     int              `json:"action_value"`
+	LatencyMs       float64          `json:"latency_ms"`
+}
+
+func (rt *Runtime) BackendsHandler(w rest.ResponseWriter, r *rest.Request) {
 	backendResponses := []BackendResponse{}
-	for _, bv := range backends {
+	for _, bv := range rt.Backends {
 		newBV := BackendResponse{
 			Name: bv.Info().Name,
 			Kind: bv.Info().Kind,
 		}
 		backendResponses = append(backendResponses, newBV)
 	}
+	sort.Slice(backendResponses, func(i, j int) bool {
+		return backendResponses[i].Name < backendResponses[j].Name
+	})
 
+	w.WriteJson(backendResponses)
+}
+
+// BackendHandler checks the connectivity of a backend and reports the outcome of its recent queries
+func (rt *Runtime) BackendHandler(w rest.ResponseWriter, r *rest.Request) {
+	name := r.PathParam("name")
+	b, ok := rt.Backends[name].(*backend.CachedBackend)
+	if !ok {
+		rest.Error(w, "Unknown backend: "+name, http.StatusNotFound)
+		return
+	}
+
+	check := CheckResponse{Supported: true}
+	start := time.Now()
+	err := b.Check()
+	check.LatencyMs = float64(time.Since(start)) / float64(time.Millisecond)
+	switch err {
+	case nil:
+		check.OK = true
+	case backend.ErrCheckUnsupported:
+		check.Supported = false
+	default:
+		log.Errorf("Check of backend %s failed: %s", name, err)
+		check.Error = err.Error()
+	}
+
+	w.WriteJson(&BackendStatusResponse{
+		Name:   name,
+		Kind:   b.Info().Kind,
+		Check:  check,
+		Health: b.Health(),
+	})
+}
+
+// BackendTestHandler runs a rule's query once, bypassing the cache, and reports the datapoints it read and the decision it would lead to
+func (rt *Runtime) BackendTestHandler(w rest.ResponseWriter, r *rest.Request) {
+	var t BackendTestRequest
+	err := r.DecodeJsonPayload(&t)
+	if err != nil {
+		log.Errorln(err)
+		rest.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	group, err := rt.Group(t.Job, t.Group)
 	if err != nil {
-		log.Errorf("Problem getting backends: %s", err.Error())
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	rule, ok := group.Rules[t.Rule]
+	if !ok {
+		rest.Error(w, "Unknown rule: "+t.Rule, http.StatusNotFound)
+		return
+	}
+	b, ok := rt.Backends[rule.Backend].(*backend.CachedBackend)
+	if !ok {
+		rest.Error(w, "Unknown backend: "+rule.Backend, http.StatusNotFound)
+		return
+	}
+
+	start := time.Now()
+	value, datapoints, err := b.Sample(*rule)
+	latency := float64(time.Since(start)) / float64(time.Millisecond)
+	if err != nil {
+		log.Errorf("Problem getting value for rule %s: %s", rule.Name, err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.WriteJson(backendResponses)
+
+	w.WriteJson(&BackendTestResponse{
+		Backend:         rule.Backend,
+		Value:           value,
+		Datapoints:      datapoints,
+		Comparison:      rule.Comparison,
+		ComparisonValue: rule.ComparisonValue,
+		Triggered:       backend.Triggered(rule, value),
+		Action:          rule.Action,
+		ActionValue:     rule.ActionValue,
+		LatencyMs:       latency,
+	})
 }
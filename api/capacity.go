@@ -2,15 +2,13 @@ package api
 
 import (
 	"net/http"
-	"os"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	log "github.com/sirupsen/logrus"
-	"github.com/underarmour/libra/config"
-	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/events"
 )
 
-func CapacityHandler(w rest.ResponseWriter, r *rest.Request) {
+func (rt *Runtime) CapacityHandler(w rest.ResponseWriter, r *rest.Request) {
 	var t ScaleRequest
 	err := r.DecodeJsonPayload(&t)
 	if err != nil {
@@ -19,21 +17,12 @@ func CapacityHandler(w rest.ResponseWriter, r *rest.Request) {
 		return
 	}
 	defer r.Body.Close()
-	config, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
+	configGroup, err := rt.Group(t.Job, t.Group)
 	if err != nil {
-		log.Errorf("Failed to read or parse config file: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	log.Info("Loaded and parsed configuration file")
-	n, err := nomad.NewClient(config.Nomad)
-	if err != nil {
-		log.Errorf("Failed to create Nomad Client: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
 	}
-	log.Info("Successfully created Nomad Client")
-
-	configGroup := config.Jobs[t.Job].Groups[t.Group]
-	evalID, newCount, err := nomad.SetCapacity(n, t.Job, t.Group, t.Count, configGroup.MinCount, configGroup.MaxCount)
+	evalID, newCount, err := rt.setCapacity(events.Event{Source: "api", Job: t.Job, Group: t.Group}, t.Count, configGroup.MinCount, configGroup.MaxCount)
 	if err != nil {
 		log.Error("Problem scaling the task group " + err.Error())
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/events"
+)
+
+// eventsHeartbeat keeps idle streams open through proxies
+const eventsHeartbeat = 15 * time.Second
+
+type eventFilter struct {
+	job   string
+	group string
+	types map[string]bool
+}
+
+func (f eventFilter) matches(e events.Event) bool {
+	if f.job != "" && e.Job != f.job {
+		return false
+	}
+	if f.group != "" && e.Group != f.group {
+		return false
+	}
+	return len(f.types) == 0 || f.types[e.Type]
+}
+
+// EventsHandler streams events as they are published, as server-sent events
+// named after their type with the event as JSON data. The job, group and type
+// query parameters filter the stream, type is a comma separated list.
+func (rt *Runtime) EventsHandler(w rest.ResponseWriter, r *rest.Request) {
+	q := r.URL.Query()
+	filter := eventFilter{job: q.Get("job"), group: q.Get("group"), types: map[string]bool{}}
+	known := map[string]bool{}
+	for _, t := range events.Types {
+		known[t] = true
+	}
+	if types := q.Get("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			if !known[t] {
+				rest.Error(w, "Unknown event type: "+t, http.StatusBadRequest)
+				return
+			}
+			filter.types[t] = true
+		}
+	}
+
+	writer, ok := w.(http.ResponseWriter)
+	flusher, canFlush := w.(http.Flusher)
+	if !ok || !canFlush {
+		rest.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, stop := rt.Events.Subscribe(100)
+	defer stop()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(writer, ": heartbeat\n\n")
+		case e := <-ch:
+			if !filter.matches(e) {
+				continue
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				log.Errorf("Problem encoding %s event: %s", e.Type, err)
+				continue
+			}
+			fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", e.Type, b)
+		}
+		flusher.Flush()
+	}
+}
@@ -2,21 +2,29 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"os"
+	"sort"
+	"strings"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/backend"
 	"github.com/underarmour/libra/config"
-	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/events"
 )
 
+// GrafanaRequest is the payload of a Grafana webhook, either from legacy
+// alerting (state, message and evalMatches) or unified alerting (status and alerts)
 type GrafanaRequest struct {
 	Title       string               `json:"title"`
+	RuleName    string               `json:"ruleName"`
 	State       string               `json:"state"`
-	Count       int                  `json:"count"`
 	Message     string               `json:"message"`
 	EvalMatches []GrafanaEvalMatches `json:"evalMatches"`
+
+	Status string         `json:"status"`
+	Alerts []GrafanaAlert `json:"alerts"`
 }
 
 type GrafanaEvalMatches struct {
@@ -24,20 +32,32 @@ type GrafanaEvalMatches struct {
 	Value  float64 `json:"value"`
 }
 
-type GrafanaMessageBody struct {
-	Job            string  `json:"job"`
-	Group          string  `json:"group"`
-	MinCount       int     `json:"min_count"`
-	MaxCount       int     `json:"max_count"`
-	MaxThreshold   float64 `json:"max_threshold"`
-	MinThreshold   float64 `json:"min_threshold"`
-	MaxAction      string  `json:"max_action"`
-	MinAction      string  `json:"min_action"`
-	MaxActionCount int     `json:"max_action_count"`
-	MinActionCount int     `json:"min_action_count"`
+// GrafanaAlert is an alert of a unified alerting payload, its values are keyed by query ref ID
+type GrafanaAlert struct {
+	Status      string              `json:"status"`
+	Labels      map[string]string   `json:"labels"`
+	Annotations map[string]string   `json:"annotations"`
+	Values      map[string]*float64 `json:"values"`
+	Fingerprint string              `json:"fingerprint"`
+}
+
+type GrafanaResult struct {
+	Policy   string  `json:"policy"`
+	Job      string  `json:"job,omitempty"`
+	Group    string  `json:"group,omitempty"`
+	Value    float64 `json:"value"`
+	Amount   int     `json:"amount"`
+	Eval     string  `json:"eval,omitempty"`
+	NewCount int     `json:"new_count,omitempty"`
+	Error    string  `json:"error,omitempty"`
 }
 
-func GrafanaHandler(w rest.ResponseWriter, r *rest.Request) {
+// GrafanaHandler scales task groups according to the grafana_policy referenced
+// by an alert: with the policy query parameter, a libra_policy label or
+// annotation on unified alerts, or the message of legacy alerts (either the
+// policy name or {"policy": "<name>"}). The values of every match referencing a
+// policy are aggregated and compared to its thresholds.
+func (rt *Runtime) GrafanaHandler(w rest.ResponseWriter, r *rest.Request) {
 	var t GrafanaRequest
 	err := r.DecodeJsonPayload(&t)
 	if err != nil {
@@ -46,55 +66,139 @@ func GrafanaHandler(w rest.ResponseWriter, r *rest.Request) {
 		return
 	}
 	defer r.Body.Close()
+	policyParam := r.URL.Query().Get("policy")
 
-	var mb GrafanaMessageBody
-	if err := json.Unmarshal([]byte(t.Message), &mb); err != nil {
-		log.Errorf("Problem parsing Grafana webhook json %s: %s", t.Message, err)
-		rest.Error(w, err.Error(), http.StatusBadRequest)
-		return
+	values := map[string][]float64{}
+	if t.Alerts != nil {
+		log.Infof("Received Grafana webhook: %s (%s, %d alerts)", t.Title, t.Status, len(t.Alerts))
+		for _, alert := range t.Alerts {
+			if alert.Status != "firing" {
+				continue
+			}
+			policy := grafanaAlertPolicy(policyParam, alert)
+			if policy == "" {
+				log.Warnf("Ignoring Grafana alert %s without libra_policy", alert.Fingerprint)
+				continue
+			}
+			values[policy] = append(values[policy], grafanaAlertValues(rt.Config.GrafanaPolicies[policy], alert)...)
+		}
+	} else {
+		log.Infof("Received Grafana webhook: %s (%s): %s", t.Title, t.State, t.Message)
+		if t.State != "alerting" || len(t.EvalMatches) == 0 {
+			log.Infof("Alert %s has been cleared. Doing nothing...", t.Title)
+			w.WriteJson([]GrafanaResult{})
+			return
+		}
+		policy := policyParam
+		if policy == "" {
+			policy, err = grafanaMessagePolicy(t.Message)
+			if err != nil {
+				log.Errorf("Problem parsing Grafana alert message %s: %s", t.Message, err)
+				rest.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		for _, match := range t.EvalMatches {
+			values[policy] = append(values[policy], match.Value)
+		}
 	}
-	log.Infof("Received Grafana webhook: %v", t.Message)
-	config, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-	if err != nil {
-		log.Errorf("Failed to read or parse config file: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
+
+	results := []GrafanaResult{}
+	for name, vs := range values {
+		results = append(results, rt.applyGrafanaPolicy(name, vs))
 	}
-	log.Info("Loaded and parsed configuration file")
-	n, err := nomad.NewClient(config.Nomad)
-	if err != nil {
-		log.Errorf("Failed to create Nomad Client: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Policy < results[j].Policy
+	})
+	w.WriteJson(results)
+}
+
+func (rt *Runtime) applyGrafanaPolicy(name string, values []float64) GrafanaResult {
+	result := GrafanaResult{Policy: name}
+	policy, ok := rt.Config.GrafanaPolicies[name]
+	if !ok {
+		log.Errorf("Unknown grafana_policy: %s", name)
+		result.Error = "Unknown grafana_policy: " + name
+		return result
 	}
-	log.Info("Successfully created Nomad Client")
+	result.Job = policy.Job
+	result.Group = policy.Group
 
-	var amount int
-	// TODO: Right now this only grabs the first match. Really, we should take all of them and average them together
-	if len(t.EvalMatches) == 0 {
-		log.Infof("Alert %s has been cleared. Doing nothing...", t.Title)
-		return
+	value, err := backend.Aggregate(policy.Aggregation, values)
+	if err != nil {
+		log.Errorf("Problem aggregating values for grafana_policy %s: %s", name, err)
+		result.Error = err.Error()
+		return result
 	}
-	if t.EvalMatches[0].Value < mb.MinThreshold {
-		amount = -mb.MinActionCount
-	} else if t.EvalMatches[0].Value > mb.MaxThreshold {
-		amount = mb.MaxActionCount
+	result.Value = value
+
+	var threshold float64
+	if policy.MaxActionCount > 0 && value > policy.MaxThreshold {
+		result.Amount, threshold = policy.MaxActionCount, policy.MaxThreshold
+	} else if policy.MinActionCount > 0 && value < policy.MinThreshold {
+		result.Amount, threshold = -policy.MinActionCount, policy.MinThreshold
 	} else {
-		w.WriteHeader(http.StatusOK)
-		return
+		log.Infof("Value %.2f of grafana_policy %s is within its thresholds. Doing nothing...", value, name)
+		return result
 	}
 
-	evalID, newCount, err := nomad.Scale(n, mb.Job, mb.Group, amount, mb.MinCount, mb.MaxCount)
+	configGroup, err := rt.Group(policy.Job, policy.Group)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	evalID, newCount, err := rt.scale(events.Event{Source: "grafana", Job: policy.Job, Group: policy.Group, Rule: name, Value: &value, Threshold: &threshold}, result.Amount, configGroup.MinCount, configGroup.MaxCount)
 	if err != nil {
 		log.Error("Problem scaling the task group " + err.Error())
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-	} else {
-		log.Infoln("Scaled it! Evaluation " + evalID)
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		respBody := &ScaleResponse{
-			Eval:     evalID,
-			NewCount: newCount,
+		result.Error = err.Error()
+		return result
+	}
+	log.Infof("Scaled %s/%s by %d to %d! Evaluation %s", policy.Job, policy.Group, result.Amount, newCount, evalID)
+	result.Eval = evalID
+	result.NewCount = newCount
+	return result
+}
+
+// grafanaMessagePolicy reads the policy name from a legacy alert message
+func grafanaMessagePolicy(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "{") {
+		if message == "" {
+			return "", fmt.Errorf("alert does not reference a grafana_policy")
 		}
+		return message, nil
+	}
+
+	var body struct {
+		Policy string `json:"policy"`
+	}
+	if err := json.Unmarshal([]byte(message), &body); err != nil {
+		return "", err
+	}
+	if body.Policy == "" {
+		return "", fmt.Errorf("alert message does not set policy")
+	}
+	return body.Policy, nil
+}
 
-		w.WriteJson(respBody)
+func grafanaAlertPolicy(param string, alert GrafanaAlert) string {
+	if param != "" {
+		return param
+	}
+	if policy := alert.Labels["libra_policy"]; policy != "" {
+		return policy
+	}
+	return alert.Annotations["libra_policy"]
+}
+
+// grafanaAlertValues returns the values of an alert's queries, only the policy's ref_id when it sets one
+func grafanaAlertValues(policy *config.GrafanaPolicy, alert GrafanaAlert) []float64 {
+	values := []float64{}
+	for refID, v := range alert.Values {
+		if v == nil || (policy != nil && policy.RefID != "" && refID != policy.RefID) {
+			continue
+		}
+		values = append(values, *v)
 	}
+	return values
 }
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/events"
+)
+
+// PauseRequest optionally limits how long a pause lasts and records why
+type PauseRequest struct {
+	// Duration is a Go duration such as "2h", the pause lasts until resumed when it is empty
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// Pause stops automatic scaling of every job, of a job or of a task group.
+// Job and Group are empty for global pauses, Group for job-wide pauses.
+type Pause struct {
+	Job    string     `json:"job,omitempty"`
+	Group  string     `json:"group,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+	Since  time.Time  `json:"since"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// PausedError is returned when automatic scaling of a group is paused
+type PausedError struct {
+	Job   string
+	Group string
+	Pause Pause
+}
+
+func (e *PausedError) Error() string {
+	s := fmt.Sprintf("autoscaling of %s/%s is paused", e.Job, e.Group)
+	if e.Pause.Until != nil {
+		s += " until " + e.Pause.Until.Format(time.RFC3339)
+	}
+	if e.Pause.Reason != "" {
+		s += ": " + e.Pause.Reason
+	}
+	return s
+}
+
+type pauseScope struct {
+	job, group string
+}
+
+// pauses are kept in memory, a restart resumes everything
+type pauses struct {
+	lock   sync.Mutex
+	scopes map[pauseScope]Pause
+}
+
+func newPauses() *pauses {
+	return &pauses{scopes: map[pauseScope]Pause{}}
+}
+
+// active returns the pause applying to a group, global first, then job-wide
+func (p *pauses) active(job, group string, now time.Time) (Pause, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, scope := range []pauseScope{{}, {job: job}, {job: job, group: group}} {
+		pause, ok := p.scopes[scope]
+		if !ok {
+			continue
+		}
+		if pause.Until != nil && !now.Before(*pause.Until) {
+			delete(p.scopes, scope)
+			continue
+		}
+		return pause, true
+	}
+	return Pause{}, false
+}
+
+// list returns the pauses that have not expired
+func (p *pauses) list(now time.Time) []Pause {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	list := []Pause{}
+	for scope, pause := range p.scopes {
+		if pause.Until != nil && !now.Before(*pause.Until) {
+			delete(p.scopes, scope)
+			continue
+		}
+		list = append(list, pause)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Job != list[j].Job {
+			return list[i].Job < list[j].Job
+		}
+		return list[i].Group < list[j].Group
+	})
+	return list
+}
+
+func (p *pauses) set(pause Pause) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.scopes[pauseScope{job: pause.Job, group: pause.Group}] = pause
+}
+
+// remove deletes the pause of a scope and the pauses within it, and reports whether there were any
+func (p *pauses) remove(job, group string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	removed := false
+	for scope := range p.scopes {
+		if (job == "" || scope.job == job) && (group == "" || scope.group == group) {
+			delete(p.scopes, scope)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// Paused returns the pause applying to a group, if any
+func (rt *Runtime) Paused(job, group string) (Pause, bool) {
+	return rt.pauses.active(job, group, time.Now())
+}
+
+// PausesHandler lists the pauses in effect
+func (rt *Runtime) PausesHandler(w rest.ResponseWriter, r *rest.Request) {
+	w.WriteJson(rt.pauses.list(time.Now()))
+}
+
+// PauseHandler stops automatic scaling of every job, of the job in the path or of its group.
+// Scaling through /scale and /capacity is still allowed.
+func (rt *Runtime) PauseHandler(w rest.ResponseWriter, r *rest.Request) {
+	job, group := r.PathParam("job"), r.PathParam("group")
+	if err := rt.checkScope(job, group); err != nil {
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var t PauseRequest
+	if r.ContentLength != 0 {
+		if err := r.DecodeJsonPayload(&t); err != nil {
+			log.Errorln(err)
+			rest.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	pause := Pause{Job: job, Group: group, Reason: t.Reason, Since: time.Now()}
+	if t.Duration != "" {
+		d, err := time.ParseDuration(t.Duration)
+		if err != nil || d <= 0 {
+			rest.Error(w, "duration must be a positive duration such as 2h", http.StatusBadRequest)
+			return
+		}
+		until := pause.Since.Add(d)
+		pause.Until = &until
+	}
+	rt.pauses.set(pause)
+
+	e := events.Event{Type: events.Paused, Source: "api", Job: job, Group: group, Reason: pause.Reason, Until: pause.Until}
+	log.Info(e.String())
+	rt.Events.Publish(e)
+	w.WriteJson(&pause)
+}
+
+// ResumeHandler lifts the pause of every job, of the job in the path or of its group,
+// including the pauses of the jobs and groups within that scope
+func (rt *Runtime) ResumeHandler(w rest.ResponseWriter, r *rest.Request) {
+	job, group := r.PathParam("job"), r.PathParam("group")
+	if err := rt.checkScope(job, group); err != nil {
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !rt.pauses.remove(job, group) {
+		// a wider pause cannot be lifted for part of its scope
+		if pause, ok := rt.pauses.active(job, group, time.Now()); ok && job != "" {
+			scope := "a global pause"
+			if pause.Job != "" {
+				scope = "the pause of job " + pause.Job
+			}
+			rest.Error(w, fmt.Sprintf("Autoscaling is still paused by %s, resume it instead", scope), http.StatusConflict)
+			return
+		}
+		rest.Error(w, "Autoscaling is not paused", http.StatusNotFound)
+		return
+	}
+
+	e := events.Event{Type: events.Resumed, Source: "api", Job: job, Group: group}
+	log.Info(e.String())
+	rt.Events.Publish(e)
+	w.WriteJson(rt.pauses.list(time.Now()))
+}
+
+func (rt *Runtime) checkScope(job, group string) error {
+	if group != "" {
+		_, err := rt.Group(job, group)
+		return err
+	}
+	if _, ok := rt.Config.Jobs[job]; job != "" && !ok {
+		return fmt.Errorf("Unknown job: %s", job)
+	}
+	return nil
+}
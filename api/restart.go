@@ -2,11 +2,9 @@ package api
 
 import (
 	"net/http"
-	"os"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	log "github.com/sirupsen/logrus"
-	"github.com/underarmour/libra/config"
 	"github.com/underarmour/libra/nomad"
 )
 
@@ -30,7 +28,7 @@ func NewRestartRequest(job, group, task, image string) *RestartRequest {
 	}
 }
 
-func RestartHandler(w rest.ResponseWriter, r *rest.Request) {
+func (rt *Runtime) RestartHandler(w rest.ResponseWriter, r *rest.Request) {
 	var t RestartRequest
 	err := r.DecodeJsonPayload(&t)
 	if err != nil {
@@ -41,20 +39,7 @@ func RestartHandler(w rest.ResponseWriter, r *rest.Request) {
 	}
 	defer r.Body.Close()
 
-	config, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-	if err != nil {
-		log.Errorf("Failed to read or parse config file: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	log.Info("Loaded and parsed configuration file")
-	n, err := nomad.NewClient(config.Nomad)
-	if err != nil {
-		log.Errorf("Failed to create Nomad Client: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	log.Info("Successfully created Nomad Client")
-
-	evalID, err := nomad.Restart(n, t.Job, t.Group, t.Task, t.Image)
+	evalID, err := nomad.Restart(rt.Nomad, t.Job, t.Group, t.Task, t.Image)
 	if err != nil {
 		log.Error("Problem restarting the job " + err.Error())
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
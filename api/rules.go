@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/backend"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/structs"
+	"gopkg.in/robfig/cron.v2"
+)
+
+// Schedule evaluates a rule on its cron schedule
+func (rt *Runtime) Schedule(cr *cron.Cron, job string, group *nomad.Group, r *structs.Rule) (cron.EntryID, error) {
+	id, err := cr.AddFunc(r.Period, func() {
+		n := rand.Intn(10) // offset cron jobs slightly so they don't collide
+		time.Sleep(time.Duration(n) * time.Second)
+		rt.Evaluate(job, group, r)
+	})
+	if err != nil {
+		return id, err
+	}
+	rt.cron = cr
+	rt.rules.update(ruleKey{job: job, group: group.Name, rule: r.Name}, func(state *RuleState) {
+		state.entry = id
+	})
+	return id, nil
+}
+
+// Evaluate queries the backend of a rule and scales its group when the rule triggers
+func (rt *Runtime) Evaluate(job string, group *nomad.Group, r *structs.Rule) error {
+	if r.BackendInstance == nil {
+		log.Errorf("No BackendInstance set")
+		return errors.New("no BackendInstance set")
+	}
+	e := events.Event{Source: "rule", Job: job, Group: group.Name, Rule: r.Name, Backend: r.Backend}
+	key := ruleKey{job: job, group: group.Name, rule: r.Name}
+
+	value, err := r.BackendInstance.GetValue(*r)
+	if err != nil {
+		rt.rules.recordValue(key, 0, false, err)
+		log.Errorf("problem getting value for metric %s: %s", r.Name, err)
+		e.Type = events.BackendError
+		e.Error = err.Error()
+		// the rule's own failures, successes of other rules on the backend don't reset them
+		e.FailingSince = rt.rules.get(key).FailingSince
+		rt.Events.Publish(e)
+		return err
+	}
+	threshold := r.ComparisonValue
+	e.Value, e.Threshold = &value, &threshold
+
+	evaluated := e
+	evaluated.Type = events.RuleEvaluated
+	evaluated.Comparison = r.Comparison
+	evaluated.Triggered = backend.Triggered(r, value)
+	rt.rules.recordValue(key, value, evaluated.Triggered, nil)
+	rt.Events.Publish(evaluated)
+
+	if !evaluated.Triggered {
+		log.Debugln("Not scaling")
+		return nil
+	}
+	// paused rules keep being evaluated for metrics and the event stream
+	if _, paused := rt.Paused(job, group.Name); paused {
+		log.Infof("Rule %s triggered but autoscaling of %s/%s is paused. Doing nothing...", r.Name, job, group.Name)
+		return nil
+	}
+
+	switch r.Action {
+	case "increase_count":
+		count := r.ActionValue
+		log.Infof("Metric %s/%s was %.2f, which is above the threshold %.2f. Attempting to increase count of %s/%s by %d", r.MetricNamespace, r.MetricName, value, r.ComparisonValue, job, group.Name, count)
+		_, _, err := rt.scale(e, count, group.MinCount, group.MaxCount)
+		if err != nil {
+			log.Errorf("problem scaling nomad job/group %s/%s: %s", job, group.Name, err)
+			return err
+		}
+	case "decrease_count":
+		count := -r.ActionValue
+		log.Infof("Metric %s/%s was %.2f, which is below the threshold %.2f. Attempting to decrease count of %s/%s by %d", r.MetricNamespace, r.MetricName, value, r.ComparisonValue, job, group.Name, -count)
+		evaluation, newCount, err := rt.scale(e, count, group.MinCount, group.MaxCount)
+		if err != nil {
+			log.Errorf("Problem scaling nomad job/group %s/%s: %s", job, group.Name, err)
+			return err
+		}
+		log.Infof("Scaled %s/%s to %d successfully with evaluation ID %s", job, group.Name, newCount, evaluation)
+	default:
+		log.Errorln("Autoscaling action did not match. Doing nothing...")
+	}
+	return nil
+}
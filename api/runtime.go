@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	nomadapi "github.com/hashicorp/nomad/api"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/backend"
+	"github.com/underarmour/libra/config"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/metrics"
+	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/notifier"
+	"github.com/underarmour/libra/sns"
+	"gopkg.in/robfig/cron.v2"
+)
+
+// Runtime is the state of a running server: the configuration it was started
+// with, its Nomad client and its backend instances. It is loaded once and
+// shared by the rules and the API handlers, which are its methods, so requests
+// see a consistent snapshot and don't reread the configuration directory.
+type Runtime struct {
+	Config   *config.RootConfig
+	Nomad    *nomadapi.Client
+	Backends backend.ConfiguredBackends
+	// SNS verifies the signatures of SNS messages, caching signing certificates
+	SNS *sns.Verifier
+	// Events receives what the server does, for notifiers and other subscribers
+	Events *events.Bus
+
+	pauses *pauses
+	rules  *ruleStates
+	cron   *cron.Cron
+	// stop ends the subscribers of Events
+	stop []func()
+}
+
+// NewRuntime reads the configuration directory and creates the clients it describes
+func NewRuntime(confDir string) (*Runtime, error) {
+	conf, err := config.NewConfig(confDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read or parse config file: %s", err)
+	}
+	log.Info("Loaded and parsed configuration file")
+
+	n, err := nomad.NewClient(nomad.Config{Address: nomad.Address(conf.Nomad)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Nomad client: %s", err)
+	}
+	log.Info("Successfully created Nomad Client")
+
+	if err := backend.DiscoverPlugins(conf.PluginDir); err != nil {
+		return nil, fmt.Errorf("problem loading backend plugins: %s", err)
+	}
+	backends, err := backend.InitializeBackends(conf.Backends)
+	if err != nil {
+		return nil, err
+	}
+
+	// rules reach their backend through the shared, cached instances
+	for jobName, job := range conf.Jobs {
+		for groupName, group := range job.Groups {
+			for ruleName, rule := range group.Rules {
+				b, ok := backends[rule.Backend]
+				if !ok {
+					backends.Close()
+					return nil, fmt.Errorf("Unknown backend: %s (%s/%s/%s)", rule.Backend, jobName, groupName, ruleName)
+				}
+				rule.BackendInstance = b
+			}
+		}
+	}
+
+	notifiers := []*notifier.Notifier{}
+	for _, nc := range conf.Notifiers {
+		nt, err := notifier.NewNotifier(nc)
+		if err != nil {
+			backends.Close()
+			return nil, err
+		}
+		notifiers = append(notifiers, nt)
+	}
+
+	var publisher *metrics.Publisher
+	if len(conf.Metrics.Backends) > 0 {
+		publisher, err = metrics.NewPublisher(conf.Metrics, backends, n)
+		if err != nil {
+			backends.Close()
+			return nil, err
+		}
+	}
+
+	rt := &Runtime{
+		Config:   conf,
+		Nomad:    n,
+		Backends: backends,
+		SNS:      sns.NewVerifier(),
+		Events:   events.NewBus(),
+		pauses:   newPauses(),
+		rules:    newRuleStates(),
+	}
+	rt.stop = append(rt.stop, notifier.Run(rt.Events, notifiers))
+	if publisher != nil {
+		rt.stop = append(rt.stop, publisher.Run(rt.Events))
+	}
+	return rt, nil
+}
+
+// Group returns the configuration of a task group
+func (rt *Runtime) Group(job, group string) (*nomad.Group, error) {
+	j, ok := rt.Config.Jobs[job]
+	if !ok {
+		return nil, fmt.Errorf("Unknown job: %s", job)
+	}
+	g, ok := j.Groups[group]
+	if !ok {
+		return nil, fmt.Errorf("Unknown group: %s/%s", job, group)
+	}
+	return g, nil
+}
+
+// Close stops the notifiers and metrics, and releases the backends
+func (rt *Runtime) Close() {
+	for _, stop := range rt.stop {
+		stop()
+	}
+	rt.Backends.Close()
+}
+
+// scale changes the count of a group by amount within min and max, and
+// publishes the outcome as an event completing e
+func (rt *Runtime) scale(e events.Event, amount, min, max int) (string, int, error) {
+	if err := rt.checkPaused(e); err != nil {
+		return "", 0, err
+	}
+	evalID, count, err := nomad.Scale(rt.Nomad, e.Job, e.Group, amount, min, max)
+	e.Amount = amount
+	if err == nil {
+		e.OldCount, e.NewCount, e.Eval = count-amount, count, evalID
+	} else {
+		e.OldCount = count
+	}
+	rt.publishScaling(e, err)
+	return evalID, count, err
+}
+
+// setCapacity sets the count of a group within min and max, and publishes
+// the outcome as an event completing e
+func (rt *Runtime) setCapacity(e events.Event, count, min, max int) (string, int, error) {
+	if err := rt.checkPaused(e); err != nil {
+		return "", 0, err
+	}
+	oldCount, err := nomad.Count(rt.Nomad, e.Job, e.Group)
+	if err != nil {
+		rt.publishScaling(e, err)
+		return "", 0, err
+	}
+	evalID, newCount, err := nomad.SetCapacity(rt.Nomad, e.Job, e.Group, count, min, max)
+	e.Amount, e.OldCount = count-oldCount, oldCount
+	if err == nil {
+		e.NewCount, e.Eval = newCount, evalID
+	}
+	rt.publishScaling(e, err)
+	return evalID, newCount, err
+}
+
+// checkPaused refuses automatic scaling of paused groups, requests to the
+// scaling API are operators acting by hand and always go through
+func (rt *Runtime) checkPaused(e events.Event) error {
+	if e.Source == "api" {
+		return nil
+	}
+	if pause, ok := rt.Paused(e.Job, e.Group); ok {
+		err := &PausedError{Job: e.Job, Group: e.Group, Pause: pause}
+		log.Infof("Not scaling (%s): %s", e.Source, err)
+		return err
+	}
+	return nil
+}
+
+func (rt *Runtime) publishScaling(e events.Event, err error) {
+	switch err := err.(type) {
+	case nil:
+		if e.Amount == 0 {
+			return
+		}
+		e.Type = events.ScaleUp
+		if e.Amount < 0 {
+			e.Type = events.ScaleDown
+		}
+	case *nomad.RangeError:
+		e.Type = events.LimitReached
+		e.NewCount = err.Count
+		e.Error = err.Error()
+	default:
+		e.Type = events.ScaleFailed
+		e.Error = err.Error()
+	}
+	if e.Source == "rule" {
+		e.Time = time.Now()
+		rt.rules.update(ruleKey{job: e.Job, group: e.Group, rule: e.Rule}, func(state *RuleState) {
+			state.LastAction = &e
+		})
+	}
+	rt.Events.Publish(e)
+}
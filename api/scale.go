@@ -1,13 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
-	"os"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	log "github.com/sirupsen/logrus"
-	"github.com/underarmour/libra/config"
-	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/events"
 )
 
 type ScaleRequest struct {
@@ -29,7 +28,22 @@ func NewScaleRequest(job, group string, count int) *ScaleRequest {
 	}
 }
 
-func ScaleHandler(w rest.ResponseWriter, r *rest.Request) {
+// actionAmount turns a rule-style action and its value into a change of count
+func actionAmount(action string, value int) (int, error) {
+	if value <= 0 {
+		return 0, fmt.Errorf("the amount to scale by must be positive, got %d", value)
+	}
+	switch action {
+	case "increase_count":
+		return value, nil
+	case "decrease_count":
+		return -value, nil
+	default:
+		return 0, fmt.Errorf("action must be increase_count or decrease_count, got %q", action)
+	}
+}
+
+func (rt *Runtime) ScaleHandler(w rest.ResponseWriter, r *rest.Request) {
 	var t ScaleRequest
 	err := r.DecodeJsonPayload(&t)
 	if err != nil {
@@ -39,25 +53,17 @@ func ScaleHandler(w rest.ResponseWriter, r *rest.Request) {
 	}
 	defer r.Body.Close()
 
-	config, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-	if err != nil {
-		log.Errorf("Failed to read or parse config file: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	log.Info("Loaded and parsed configuration file")
-	n, err := nomad.NewClient(config.Nomad)
-	if err != nil {
-		log.Errorf("Failed to create Nomad Client: %s", err)
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	log.Info("Successfully created Nomad Client")
-
 	if t.Count == 0 {
 		log.Error("Amount to increment or decrement cannot be 0.")
-		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		rest.Error(w, "Amount to increment or decrement cannot be 0", http.StatusBadRequest)
+		return
+	}
+	configGroup, err := rt.Group(t.Job, t.Group)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
 	}
-	configGroup := config.Jobs[t.Job].Groups[t.Group]
-	evalID, newCount, err := nomad.Scale(n, t.Job, t.Group, t.Count, configGroup.MinCount, configGroup.MaxCount)
+	evalID, newCount, err := rt.scale(events.Event{Source: "api", Job: t.Job, Group: t.Group}, t.Count, configGroup.MinCount, configGroup.MaxCount)
 	if err != nil {
 		log.Error("Problem scaling the task group " + err.Error())
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/sns"
+)
+
+// CloudWatchAlarmMessage is the message of an SNS notification sent by a CloudWatch alarm
+type CloudWatchAlarmMessage struct {
+	AlarmName        string `json:"AlarmName"`
+	AlarmDescription string `json:"AlarmDescription"`
+	AlarmArn         string `json:"AlarmArn"`
+	NewStateValue    string `json:"NewStateValue"`
+	NewStateReason   string `json:"NewStateReason"`
+	OldStateValue    string `json:"OldStateValue"`
+	StateChangeTime  string `json:"StateChangeTime"`
+	Region           string `json:"Region"`
+}
+
+type SNSResult struct {
+	Alarm    string `json:"alarm"`
+	State    string `json:"state"`
+	Job      string `json:"job,omitempty"`
+	Group    string `json:"group,omitempty"`
+	Amount   int    `json:"amount"`
+	Eval     string `json:"eval,omitempty"`
+	NewCount int    `json:"new_count,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// descriptionTag matches libra_job=web style tags in an alarm description
+var descriptionTag = regexp.MustCompile(`\b(libra_[a-z]+)=(\S+)`)
+
+// SNSHandler receives SNS notifications of CloudWatch alarm state changes and
+// scales the group an alarm maps to when it goes into ALARM. The alarm is
+// mapped by a cloudwatch_alarm stanza of the same name, or by libra_job,
+// libra_group, libra_action and libra_amount tags in its description.
+// Subscriptions to the topics listed in the sns stanza are confirmed.
+func (rt *Runtime) SNSHandler(w rest.ResponseWriter, r *rest.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Errorln(err)
+		rest.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var m sns.Message
+	if err := json.Unmarshal(body, &m); err != nil {
+		log.Errorf("Problem parsing SNS message: %s", err)
+		rest.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	allowed := false
+	for _, arn := range rt.Config.SNS.TopicARNs {
+		if arn == m.TopicArn {
+			allowed = true
+		}
+	}
+	if !allowed {
+		log.Warnf("Rejecting SNS %s from topic %s, which is not in sns.topic_arns", m.Type, m.TopicArn)
+		rest.Error(w, "Topic is not accepted: "+m.TopicArn, http.StatusForbidden)
+		return
+	}
+	if err := rt.SNS.Verify(&m); err != nil {
+		log.Errorf("Problem verifying SNS message %s: %s", m.MessageId, err)
+		rest.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	switch m.Type {
+	case sns.TypeSubscriptionConfirmation:
+		if err := rt.SNS.ConfirmSubscription(&m); err != nil {
+			log.Errorf("Problem confirming subscription to %s: %s", m.TopicArn, err)
+			rest.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Infof("Confirmed subscription to %s", m.TopicArn)
+		w.WriteHeader(http.StatusOK)
+		return
+	case sns.TypeUnsubscribeConfirmation:
+		log.Infof("Unsubscribed from %s", m.TopicArn)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var alarm CloudWatchAlarmMessage
+	if err := json.Unmarshal([]byte(m.Message), &alarm); err != nil || alarm.AlarmName == "" {
+		// not an alarm, acknowledge it so that SNS does not retry
+		log.Warnf("Ignoring SNS notification %s from %s that is not a CloudWatch alarm", m.MessageId, m.TopicArn)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	log.Infof("Received CloudWatch alarm %s: %s -> %s", alarm.AlarmName, alarm.OldStateValue, alarm.NewStateValue)
+
+	// failures are reported with a 200 as SNS would otherwise retry the notification
+	w.WriteJson(rt.applyCloudWatchAlarm(alarm))
+}
+
+func (rt *Runtime) applyCloudWatchAlarm(alarm CloudWatchAlarmMessage) SNSResult {
+	result := SNSResult{Alarm: alarm.AlarmName, State: alarm.NewStateValue}
+	if alarm.NewStateValue != "ALARM" {
+		log.Infof("Alarm %s is %s. Doing nothing...", alarm.AlarmName, alarm.NewStateValue)
+		return result
+	}
+
+	var action string
+	var value int
+	if mapping, ok := rt.Config.CloudWatchAlarms[alarm.AlarmName]; ok {
+		result.Job, result.Group = mapping.Job, mapping.Group
+		action, value = mapping.Action, mapping.ActionValue
+	} else {
+		tags := map[string]string{}
+		for _, m := range descriptionTag.FindAllStringSubmatch(alarm.AlarmDescription, -1) {
+			tags[m[1]] = m[2]
+		}
+		if tags["libra_job"] == "" || tags["libra_group"] == "" {
+			log.Warnf("Alarm %s has no cloudwatch_alarm stanza nor libra_job and libra_group tags. Doing nothing...", alarm.AlarmName)
+			result.Error = "alarm is not mapped to a group"
+			return result
+		}
+		result.Job, result.Group = tags["libra_job"], tags["libra_group"]
+		action, value = tags["libra_action"], 1
+		if s := tags["libra_amount"]; s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				result.Error = "libra_amount must be a positive integer, got " + s
+				return result
+			}
+			value = n
+		}
+	}
+
+	amount, err := actionAmount(action, value)
+	if err != nil {
+		log.Errorf("Problem with alarm %s: %s", alarm.AlarmName, err)
+		result.Error = err.Error()
+		return result
+	}
+	result.Amount = amount
+
+	configGroup, err := rt.Group(result.Job, result.Group)
+	if err != nil {
+		log.Errorf("Problem scaling from alarm %s: %s", alarm.AlarmName, err)
+		result.Error = err.Error()
+		return result
+	}
+	evalID, newCount, err := rt.scale(events.Event{Source: "sns", Rule: alarm.AlarmName, Job: result.Job, Group: result.Group}, amount, configGroup.MinCount, configGroup.MaxCount)
+	if err != nil {
+		log.Errorf("Problem scaling the task group %s/%s: %s", result.Job, result.Group, err)
+		result.Error = err.Error()
+		return result
+	}
+	log.Infof("Scaled %s/%s by %d to %d! Evaluation %s", result.Job, result.Group, amount, newCount, evalID)
+	result.Eval = evalID
+	result.NewCount = newCount
+	return result
+}
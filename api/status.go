@@ -0,0 +1,216 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	nomadapi "github.com/hashicorp/nomad/api"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/structs"
+	"gopkg.in/robfig/cron.v2"
+)
+
+type JobStatus struct {
+	Name   string        `json:"name"`
+	Groups []GroupStatus `json:"groups"`
+}
+
+type GroupStatus struct {
+	Job      string `json:"job"`
+	Group    string `json:"group"`
+	MinCount int    `json:"min_count"`
+	MaxCount int    `json:"max_count"`
+	// Count is the current count in Nomad, CountError why it could not be read
+	Count      *int         `json:"count,omitempty"`
+	CountError string       `json:"count_error,omitempty"`
+	Paused     *Pause       `json:"paused,omitempty"`
+	Rules      []RuleStatus `json:"rules"`
+}
+
+// RuleStatus is the configuration of a rule and the outcome of its evaluations
+type RuleStatus struct {
+	Name            string     `json:"name"`
+	Backend         string     `json:"backend"`
+	Comparison      string     `json:"comparison"`
+	ComparisonValue float64    `json:"comparison_value"`
+	Action          string     `json:"action"`
+	ActionValue     int        `json:"action_value"`
+	Cron            string     `json:"cron"`
+	NextRun         *time.Time `json:"next_run,omitempty"`
+	RuleState
+}
+
+// RuleState is what the server remembers of the evaluations of a rule
+type RuleState struct {
+	LastEvaluation *time.Time `json:"last_evaluation,omitempty"`
+	LastValue      *float64   `json:"last_value,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	// FailingSince is the time of the first error reading the rule's value
+	// since its last successful read
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	// Triggered is the comparison result of the last value, BreachingSince
+	// when the values started triggering the rule
+	Triggered      bool       `json:"triggered"`
+	BreachingSince *time.Time `json:"breaching_since,omitempty"`
+	// LastAction is the event of the last scaling the rule asked for
+	LastAction *events.Event `json:"last_action,omitempty"`
+
+	entry cron.EntryID
+}
+
+type ruleKey struct {
+	job, group, rule string
+}
+
+type ruleStates struct {
+	lock   sync.Mutex
+	states map[ruleKey]RuleState
+}
+
+func newRuleStates() *ruleStates {
+	return &ruleStates{states: map[ruleKey]RuleState{}}
+}
+
+func (s *ruleStates) get(key ruleKey) RuleState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.states[key]
+}
+
+func (s *ruleStates) update(key ruleKey, f func(*RuleState)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state := s.states[key]
+	f(&state)
+	s.states[key] = state
+}
+
+// recordValue stores the outcome of reading a rule's value, err when it could not be read
+func (s *ruleStates) recordValue(key ruleKey, value float64, triggered bool, err error) {
+	now := time.Now()
+	s.update(key, func(state *RuleState) {
+		state.LastEvaluation = &now
+		if err != nil {
+			state.LastError = err.Error()
+			if state.FailingSince == nil {
+				state.FailingSince = &now
+			}
+			return
+		}
+		state.LastValue = &value
+		state.LastError = ""
+		state.FailingSince = nil
+		state.Triggered = triggered
+		if !triggered {
+			state.BreachingSince = nil
+		} else if state.BreachingSince == nil {
+			state.BreachingSince = &now
+		}
+	})
+}
+
+// JobsHandler returns the status of every job
+func (rt *Runtime) JobsHandler(w rest.ResponseWriter, r *rest.Request) {
+	jobs := []JobStatus{}
+	for name := range rt.Config.Jobs {
+		jobs = append(jobs, rt.jobStatus(name))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+	w.WriteJson(jobs)
+}
+
+// JobHandler returns the status of the groups of a job
+func (rt *Runtime) JobHandler(w rest.ResponseWriter, r *rest.Request) {
+	job := r.PathParam("job")
+	if _, ok := rt.Config.Jobs[job]; !ok {
+		rest.Error(w, "Unknown job: "+job, http.StatusNotFound)
+		return
+	}
+	status := rt.jobStatus(job)
+	w.WriteJson(&status)
+}
+
+// GroupHandler returns the status of a group and its rules
+func (rt *Runtime) GroupHandler(w rest.ResponseWriter, r *rest.Request) {
+	group, err := rt.Group(r.PathParam("job"), r.PathParam("group"))
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	nomadJob, err := rt.nomadJob(r.PathParam("job"))
+	status := rt.groupStatus(r.PathParam("job"), group, nomadJob, err)
+	w.WriteJson(&status)
+}
+
+func (rt *Runtime) jobStatus(name string) JobStatus {
+	status := JobStatus{Name: name, Groups: []GroupStatus{}}
+	nomadJob, err := rt.nomadJob(name)
+	for _, group := range rt.Config.Jobs[name].Groups {
+		status.Groups = append(status.Groups, rt.groupStatus(name, group, nomadJob, err))
+	}
+	sort.Slice(status.Groups, func(i, j int) bool {
+		return status.Groups[i].Group < status.Groups[j].Group
+	})
+	return status
+}
+
+// nomadJob reads a job from Nomad, once for all its groups
+func (rt *Runtime) nomadJob(name string) (*nomadapi.Job, error) {
+	job, _, err := rt.Nomad.Jobs().Info(name, &nomadapi.QueryOptions{})
+	return job, err
+}
+
+// groupStatus reports the count of the group in nomadJob, or jobErr when the job could not be read
+func (rt *Runtime) groupStatus(job string, group *nomad.Group, nomadJob *nomadapi.Job, jobErr error) GroupStatus {
+	status := GroupStatus{
+		Job:      job,
+		Group:    group.Name,
+		MinCount: group.MinCount,
+		MaxCount: group.MaxCount,
+		Rules:    []RuleStatus{},
+	}
+	if jobErr != nil {
+		status.CountError = jobErr.Error()
+	} else if tg, err := nomad.TaskGroup(nomadJob, group.Name); err != nil {
+		status.CountError = err.Error()
+	} else {
+		status.Count = tg.Count
+	}
+	if pause, ok := rt.Paused(job, group.Name); ok {
+		status.Paused = &pause
+	}
+
+	for _, rule := range group.Rules {
+		status.Rules = append(status.Rules, rt.ruleStatus(job, group.Name, rule))
+	}
+	sort.Slice(status.Rules, func(i, j int) bool {
+		return status.Rules[i].Name < status.Rules[j].Name
+	})
+	return status
+}
+
+func (rt *Runtime) ruleStatus(job, group string, rule *structs.Rule) RuleStatus {
+	status := RuleStatus{
+		Name:            rule.Name,
+		Backend:         rule.Backend,
+		Comparison:      rule.Comparison,
+		ComparisonValue: rule.ComparisonValue,
+		Action:          rule.Action,
+		ActionValue:     rule.ActionValue,
+		Cron:            rule.Period,
+		RuleState:       rt.rules.get(ruleKey{job: job, group: group, rule: rule.Name}),
+	}
+	// rules run after an offset of up to 10 seconds
+	if rt.cron != nil && status.entry != 0 {
+		if next := rt.cron.Entry(status.entry).Next; !next.IsZero() {
+			status.NextRun = &next
+		}
+	}
+	return status
+}
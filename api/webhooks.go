@@ -0,0 +1,173 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/backend"
+	"github.com/underarmour/libra/config"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/nomad"
+)
+
+type WebhookResponse struct {
+	Webhook  string `json:"webhook"`
+	Job      string `json:"job"`
+	Group    string `json:"group"`
+	Action   string `json:"action"`
+	Value    int    `json:"value"`
+	Eval     string `json:"eval"`
+	NewCount int    `json:"new_count"`
+}
+
+// WebhookHandler runs the scaling action of the webhook stanza named in the
+// path, for callers that sign the request body with the webhook's secret
+func (rt *Runtime) WebhookHandler(w rest.ResponseWriter, r *rest.Request) {
+	name := r.PathParam("name")
+	hook, ok := rt.Config.Webhooks[name]
+	if !ok {
+		rest.Error(w, "Unknown webhook: "+name, http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Errorln(err)
+		rest.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	if !validSignature(hook.Secret, body, r.Header.Get(hook.SignatureHeader)) {
+		log.Warnf("Rejecting call to webhook %s with an invalid %s header", name, hook.SignatureHeader)
+		rest.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			rest.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	resp, err := webhookAction(hook, payload)
+	if err != nil {
+		log.Errorf("Problem with call to webhook %s: %s", name, err)
+		rest.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	configGroup, err := rt.Group(resp.Job, resp.Group)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	min, max := configGroup.MinCount, configGroup.MaxCount
+	if hook.MinCount > min {
+		min = hook.MinCount
+	}
+	if hook.MaxCount > 0 && hook.MaxCount < max {
+		max = hook.MaxCount
+	}
+
+	// max_change of set_capacity applies to the difference from the current count
+	if hook.Action == "set_capacity" && hook.MaxChange > 0 {
+		count, err := nomad.Count(rt.Nomad, resp.Job, resp.Group)
+		if err != nil {
+			log.Errorf("Problem getting the count of %s/%s for webhook %s: %s", resp.Job, resp.Group, name, err)
+			rest.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if change := resp.Value - count; change > hook.MaxChange || change < -hook.MaxChange {
+			err := fmt.Errorf("setting the capacity to %d changes the count by %d, more than max_change (%d)", resp.Value, change, hook.MaxChange)
+			log.Errorf("Problem with call to webhook %s: %s", name, err)
+			rest.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	event := events.Event{Source: "webhook", Rule: name, Job: resp.Job, Group: resp.Group}
+	switch hook.Action {
+	case "scale":
+		resp.Eval, resp.NewCount, err = rt.scale(event, resp.Value, min, max)
+	case "set_capacity":
+		resp.Eval, resp.NewCount, err = rt.setCapacity(event, resp.Value, min, max)
+	}
+	if err != nil {
+		log.Errorf("Problem scaling the task group %s/%s from webhook %s: %s", resp.Job, resp.Group, name, err)
+		status := http.StatusInternalServerError
+		if _, ok := err.(*PausedError); ok {
+			status = http.StatusConflict
+		}
+		rest.Error(w, err.Error(), status)
+		return
+	}
+	log.Infof("Webhook %s: %s %s/%s with %d, new count %d! Evaluation %s", name, hook.Action, resp.Job, resp.Group, resp.Value, resp.NewCount, resp.Eval)
+	w.WriteJson(resp)
+}
+
+// webhookAction resolves the group and value of a webhook call and checks the
+// amount to scale by against max_change
+func webhookAction(hook *config.Webhook, payload interface{}) (*WebhookResponse, error) {
+	resp := &WebhookResponse{
+		Webhook: hook.Name,
+		Job:     hook.Job,
+		Group:   hook.Group,
+		Action:  hook.Action,
+	}
+	if hook.Value != nil {
+		resp.Value = *hook.Value
+	}
+
+	var err error
+	if hook.JobExpression != "" {
+		if resp.Job, err = backend.ExtractString(hook.JobExpression, payload); err != nil {
+			return nil, err
+		}
+	}
+	if hook.GroupExpression != "" {
+		if resp.Group, err = backend.ExtractString(hook.GroupExpression, payload); err != nil {
+			return nil, err
+		}
+	}
+	if hook.ValueExpression != "" {
+		v, err := backend.ExtractValue(hook.ValueExpression, payload, "")
+		if err != nil {
+			return nil, err
+		}
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("value %v is not a whole number", v)
+		}
+		resp.Value = int(v)
+	}
+
+	if hook.Action == "scale" {
+		if resp.Value == 0 {
+			return nil, fmt.Errorf("the amount to scale by cannot be 0")
+		}
+		if hook.MaxChange > 0 && (resp.Value > hook.MaxChange || resp.Value < -hook.MaxChange) {
+			return nil, fmt.Errorf("the amount to scale by (%d) is larger than max_change (%d)", resp.Value, hook.MaxChange)
+		}
+	}
+	return resp, nil
+}
+
+// validSignature checks a hex HMAC-SHA256 of the body, optionally prefixed with "sha256="
+func validSignature(secret string, body []byte, signature string) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(sig) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -0,0 +1,44 @@
+package backend
+
+import "fmt"
+
+// Aggregate reduces several values into one using the named function.
+// An empty function name defaults to "avg".
+func Aggregate(function string, values []float64) (float64, error) {
+	if len(values) == 0 {
+		return 0.0, fmt.Errorf("no values to aggregate")
+	}
+
+	switch function {
+	case "", "avg":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), nil
+	case "sum":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum, nil
+	case "max":
+		max := values[0]
+		for _, v := range values[1:] {
+			if v > max {
+				max = v
+			}
+		}
+		return max, nil
+	case "min":
+		min := values[0]
+		for _, v := range values[1:] {
+			if v < min {
+				min = v
+			}
+		}
+		return min, nil
+	default:
+		return 0.0, fmt.Errorf("unknown aggregation '%s', must be one of avg, sum, max or min", function)
+	}
+}
@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AWSConfig holds the credential options shared by the AWS backends
+type AWSConfig struct {
+	Region     string
+	Profile    string
+	RoleARN    string
+	ExternalID string
+	Endpoint   string
+}
+
+// newAWSSession creates a session for the configured region and profile,
+// plus the service config to use with it. When a role is configured the
+// credentials are obtained through STS, and a custom endpoint only applies
+// to the service itself so that STS keeps using the real AWS endpoint.
+func newAWSSession(config AWSConfig) (*session.Session, *aws.Config, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region: aws.String(config.Region),
+		},
+		Profile:           config.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serviceConfig := &aws.Config{}
+	if config.RoleARN != "" {
+		serviceConfig.Credentials = stscreds.NewCredentials(sess, config.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if config.ExternalID != "" {
+				p.ExternalID = aws.String(config.ExternalID)
+			}
+		})
+	}
+	if config.Endpoint != "" {
+		serviceConfig.Endpoint = aws.String(config.Endpoint)
+	}
+
+	return sess, serviceConfig, nil
+}
@@ -2,17 +2,68 @@ package backend
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/underarmour/libra/config"
 	"github.com/underarmour/libra/structs"
 )
 
 // ConfiguredBackends struct
 type ConfiguredBackends map[string]structs.Backender
 
+// Factory creates a backend from its configuration stanza
+type Factory func(name string, conf structs.Backend) (structs.Backender, error)
+
+var (
+	factoriesLock sync.RWMutex
+	factories     = map[string]Factory{}
+)
+
+func init() {
+	Register("cloudwatch", newCloudWatchFromConfig)
+	Register("graphite", newGraphiteFromConfig)
+	Register("nomad", newNomadMetricFromConfig)
+	Register("influxdb", newInfluxDBFromConfig)
+	Register("http", newHTTPFromConfig)
+	Register("exec", newExecFromConfig)
+	Register("consul", newConsulFromConfig)
+	Register("sqs", newSQSFromConfig)
+	Register("rabbitmq", newRabbitMQFromConfig)
+	Register("redis", newRedisFromConfig)
+	Register("kafka", newKafkaFromConfig)
+	Register("elasticsearch", newElasticsearchFromConfig)
+	Register("opensearch", newElasticsearchFromConfig)
+	Register("datadog", newDatadogFromConfig)
+	Register("newrelic", newNewRelicFromConfig)
+}
+
+// Register makes a backend kind available to the configuration, replacing any
+// factory previously registered for that kind
+func Register(kind string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	factories[kind] = factory
+}
+
+// Kinds returns every registered backend kind, sorted
+func Kinds() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	kinds := []string{}
+	for kind := range factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
 // InitializeBackends loads valid backends into a map
 func InitializeBackends(backends map[string]structs.Backend) (ConfiguredBackends, error) {
 	configuredBackends := make(ConfiguredBackends, 0)
@@ -21,61 +72,262 @@ func InitializeBackends(backends map[string]structs.Backend) (ConfiguredBackends
 		backendType := backend.Kind
 
 		if backendType == "" {
+			configuredBackends.Close()
 			return nil, fmt.Errorf("Missing backend type for '%s'", name)
 		}
 
-		switch backendType {
-		case "cloudwatch":
-			c, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-			if err != nil {
-				log.Errorf("Failed to read or parse config file: %s", err)
-				return nil, err
-			}
+		factoriesLock.RLock()
+		factory, ok := factories[backendType]
+		factoriesLock.RUnlock()
+		if !ok {
+			configuredBackends.Close()
+			return nil, fmt.Errorf("unknown backend type '%s' for backend %s, must be one of %s", backendType, name, strings.Join(Kinds(), ", "))
+		}
 
-			conf := c.Backends[name]
+		cacheTTL, err := parseTimeout(backend.CacheTTL)
+		if err != nil {
+			configuredBackends.Close()
+			return nil, fmt.Errorf("Bad cache_ttl for %s: %s", name, err)
+		}
 
-			connection, err := NewCloudWatchBackend(name, CloudWatchConfig{
-				Kind:   conf.Kind,
-				Name:   conf.Name,
-				Region: conf.Region,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("Bad configuration for %s: %s", name, err)
-			}
+		connection, err := factory(name, backend)
+		if err != nil {
+			configuredBackends.Close()
+			return nil, fmt.Errorf("Bad configuration for %s: %s", name, err)
+		}
 
-			configuredBackends[name] = connection
+		configuredBackends[name] = NewCachedBackend(connection, cacheTTL)
+	}
 
-		case "graphite":
-			c, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-			if err != nil {
-				log.Errorf("Failed to read or parse config file: %s", err)
-				return nil, err
+	return configuredBackends, nil
+}
+
+// Close releases backends holding resources, such as plugin processes
+func (c ConfiguredBackends) Close() {
+	for name, b := range c {
+		if closer, ok := b.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Errorf("Problem closing backend %s: %s", name, err)
 			}
+		}
+	}
+}
 
-			conf := c.Backends[name]
+func newCloudWatchFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	return NewCloudWatchBackend(name, CloudWatchConfig{
+		Kind:       conf.Kind,
+		Name:       conf.Name,
+		Region:     conf.Region,
+		Profile:    conf.Profile,
+		RoleARN:    conf.RoleARN,
+		ExternalID: conf.ExternalID,
+		Endpoint:   conf.Endpoint,
+	})
+}
 
-			password := conf.Password
-			if password == "" {
-				password = os.Getenv("GRAPHITE_PASSWORD")
-			}
-			connection, err := NewGraphiteBackend(name, GraphiteConfig{
-				Kind:     conf.Kind,
-				Name:     conf.Name,
-				Host:     conf.Host,
-				Username: conf.Username,
-				Password: password,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("Bad configuration for %s: %s", name, err)
-			}
+func newGraphiteFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	password := conf.Password
+	if password == "" {
+		password = os.Getenv("GRAPHITE_PASSWORD")
+	}
+	return NewGraphiteBackend(name, GraphiteConfig{
+		Kind:          conf.Kind,
+		Name:          conf.Name,
+		Host:          conf.Host,
+		Username:      conf.Username,
+		Password:      password,
+		PathPrefix:    conf.PathPrefix,
+		CarbonAddress: conf.CarbonAddress,
+	})
+}
+
+func newNomadMetricFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	return NewNomadMetricBackend(name, NomadMetricConfig{
+		Kind:    conf.Kind,
+		Name:    conf.Name,
+		Address: conf.Address,
+	})
+}
+
+func newInfluxDBFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	password := conf.Password
+	if password == "" {
+		password = os.Getenv("INFLUXDB_PASSWORD")
+	}
+	token := conf.Token
+	if token == "" {
+		token = os.Getenv("INFLUXDB_TOKEN")
+	}
+	return NewInfluxDBBackend(name, InfluxDBConfig{
+		Kind:          conf.Kind,
+		Name:          conf.Name,
+		Host:          conf.Host,
+		Username:      conf.Username,
+		Password:      password,
+		Token:         token,
+		QueryLanguage: conf.QueryLanguage,
+		Database:      conf.Database,
+		Org:           conf.Org,
+		Bucket:        conf.Bucket,
+	})
+}
+
+func newHTTPFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	return NewHTTPBackend(name, HTTPConfig{
+		Kind:     conf.Kind,
+		Name:     conf.Name,
+		URL:      conf.URL,
+		Method:   conf.Method,
+		Headers:  conf.Headers,
+		Body:     conf.Body,
+		Username: conf.Username,
+		Password: conf.Password,
+		Token:    conf.Token,
+	})
+}
+
+func newExecFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	timeout, err := parseTimeout(conf.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewExecBackend(name, ExecConfig{
+		Kind:    conf.Kind,
+		Name:    conf.Name,
+		Command: conf.Command,
+		Timeout: timeout,
+	})
+}
+
+func newConsulFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	return NewConsulBackend(name, ConsulConfig{
+		Kind:       conf.Kind,
+		Name:       conf.Name,
+		Address:    conf.Address,
+		Datacenter: conf.Datacenter,
+		Token:      conf.Token,
+	})
+}
+
+func newSQSFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	return NewSQSBackend(name, SQSConfig{
+		Kind:       conf.Kind,
+		Name:       conf.Name,
+		Region:     conf.Region,
+		Profile:    conf.Profile,
+		RoleARN:    conf.RoleARN,
+		ExternalID: conf.ExternalID,
+		Endpoint:   conf.Endpoint,
+	})
+}
 
-			configuredBackends[name] = connection
+func newRabbitMQFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	password := conf.Password
+	if password == "" {
+		password = os.Getenv("RABBITMQ_PASSWORD")
+	}
+	return NewRabbitMQBackend(name, RabbitMQConfig{
+		Kind:     conf.Kind,
+		Name:     conf.Name,
+		Host:     conf.Host,
+		Username: conf.Username,
+		Password: password,
+		VHost:    conf.VHost,
+	})
+}
 
-		default:
-			log.Fatalf("unknown backend type '%s' for backend %s", backendType, name)
-			return nil, fmt.Errorf("unknown backend %s", backendType)
+func newRedisFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	password := conf.Password
+	if password == "" {
+		password = os.Getenv("REDIS_PASSWORD")
+	}
+	database := 0
+	if conf.Database != "" {
+		var err error
+		if database, err = strconv.Atoi(conf.Database); err != nil {
+			return nil, fmt.Errorf("database must be a number: %s", err)
 		}
 	}
+	timeout, err := parseTimeout(conf.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewRedisBackend(name, RedisConfig{
+		Kind:     conf.Kind,
+		Name:     conf.Name,
+		Address:  conf.Address,
+		Password: password,
+		Database: database,
+		Timeout:  timeout,
+	})
+}
 
-	return configuredBackends, nil
+func newKafkaFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	timeout, err := parseTimeout(conf.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewKafkaBackend(name, KafkaConfig{
+		Kind:    conf.Kind,
+		Name:    conf.Name,
+		Brokers: conf.Brokers,
+		Timeout: timeout,
+	})
+}
+
+func newElasticsearchFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	password := conf.Password
+	if password == "" {
+		password = os.Getenv("ELASTICSEARCH_PASSWORD")
+	}
+	return NewElasticsearchBackend(name, ElasticsearchConfig{
+		Kind:           conf.Kind,
+		Name:           conf.Name,
+		Host:           conf.Host,
+		Username:       conf.Username,
+		Password:       password,
+		Token:          conf.Token,
+		Index:          conf.Index,
+		TimestampField: conf.TimestampField,
+	})
+}
+
+func newDatadogFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	apiKey := conf.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("DD_API_KEY")
+	}
+	appKey := conf.AppKey
+	if appKey == "" {
+		appKey = os.Getenv("DD_APP_KEY")
+	}
+	return NewDatadogBackend(name, DatadogConfig{
+		Kind:    conf.Kind,
+		Name:    conf.Name,
+		APIBase: conf.APIBase,
+		APIKey:  apiKey,
+		AppKey:  appKey,
+	})
+}
+
+func newNewRelicFromConfig(name string, conf structs.Backend) (structs.Backender, error) {
+	apiKey := conf.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("NEW_RELIC_API_KEY")
+	}
+	return NewNewRelicBackend(name, NewRelicConfig{
+		Kind:      conf.Kind,
+		Name:      conf.Name,
+		APIBase:   conf.APIBase,
+		APIKey:    apiKey,
+		AccountID: conf.AccountID,
+	})
+}
+
+// parseTimeout parses an optional duration, returning 0 when it is not set
+func parseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(timeout)
 }
@@ -0,0 +1,213 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/underarmour/libra/structs"
+)
+
+// ErrCheckUnsupported is returned by Check when the backend cannot check its connectivity
+var ErrCheckUnsupported = errors.New("backend does not support checks")
+
+var errQueryPanicked = errors.New("backend query panicked")
+
+// CachedBackend wraps a backend so that rules issuing the same query share
+// results: concurrent identical queries are coalesced into a single call, and
+// successful results are reused for TTL when it is set. It also records the
+// outcome of the queries that reach the backend.
+type CachedBackend struct {
+	Backend structs.Backender
+	TTL     time.Duration
+
+	lock     sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]*inflightCall
+	health   Health
+}
+
+// Health describes the most recent queries that reached a backend
+type Health struct {
+	LastSuccess   *time.Time `json:"last_success"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time"`
+	// FailingSince is the time of the first error since the last success
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	// LatencyMs is the duration of the most recent query
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+type cacheEntry struct {
+	value   float64
+	expires time.Time
+}
+
+type inflightCall struct {
+	done  chan struct{}
+	value float64
+	err   error
+}
+
+// NewCachedBackend wraps b with a cache of the given TTL, 0 only coalesces concurrent queries
+func NewCachedBackend(b structs.Backender, ttl time.Duration) *CachedBackend {
+	return &CachedBackend{
+		Backend:  b,
+		TTL:      ttl,
+		entries:  map[string]cacheEntry{},
+		inflight: map[string]*inflightCall{},
+	}
+}
+
+// GetValue returns a cached value, waits for an identical query in flight, or queries the backend
+func (c *CachedBackend) GetValue(rule structs.Rule) (float64, error) {
+	key := queryKey(c.Backend, rule)
+
+	c.lock.Lock()
+	if e, ok := c.entries[key]; ok {
+		if time.Now().Before(e.expires) {
+			c.lock.Unlock()
+			return e.value, nil
+		}
+		delete(c.entries, key)
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.lock.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.lock.Unlock()
+
+	// release the waiting callers even when the backend panics
+	finished := false
+	defer func() {
+		c.lock.Lock()
+		delete(c.inflight, key)
+		if !finished {
+			call.err = errQueryPanicked
+		} else if call.err == nil && c.TTL > 0 {
+			c.entries[key] = cacheEntry{value: call.value, expires: time.Now().Add(c.TTL)}
+		}
+		c.lock.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = c.Fetch(rule)
+	finished = true
+	return call.value, call.err
+}
+
+// Fetch queries the backend, bypassing the cache, and records the outcome
+func (c *CachedBackend) Fetch(rule structs.Rule) (float64, error) {
+	start := time.Now()
+	value, err := c.Backend.GetValue(rule)
+	c.record(start, err)
+	return value, err
+}
+
+// Sample is Fetch that also returns the datapoints the value was reduced
+// from, when the wrapped backend can report them
+func (c *CachedBackend) Sample(rule structs.Rule) (float64, []structs.Series, error) {
+	sampler, ok := c.Backend.(structs.Sampler)
+	if !ok {
+		value, err := c.Fetch(rule)
+		return value, nil, err
+	}
+	start := time.Now()
+	value, series, err := sampler.Sample(rule)
+	c.record(start, err)
+	return value, series, err
+}
+
+// Check runs the wrapped backend's connectivity check, when it has one
+func (c *CachedBackend) Check() error {
+	checker, ok := c.Backend.(structs.Checker)
+	if !ok {
+		return ErrCheckUnsupported
+	}
+	return checker.Check()
+}
+
+// Health returns the outcome of the most recent queries
+func (c *CachedBackend) Health() Health {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.health
+}
+
+func (c *CachedBackend) record(start time.Time, err error) {
+	now := time.Now()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.health.LatencyMs = float64(now.Sub(start)) / float64(time.Millisecond)
+	if err != nil {
+		c.health.LastError = err.Error()
+		c.health.LastErrorTime = &now
+		if c.health.FailingSince == nil {
+			c.health.FailingSince = &now
+		}
+	} else {
+		c.health.LastSuccess = &now
+		c.health.FailingSince = nil
+	}
+}
+
+// Info describes the wrapped backend
+func (c *CachedBackend) Info() *structs.Backend {
+	return c.Backend.Info()
+}
+
+// Unwrap returns the wrapped backend
+func (c *CachedBackend) Unwrap() structs.Backender {
+	return c.Backend
+}
+
+// Close closes the wrapped backend if it holds resources
+func (c *CachedBackend) Close() error {
+	if closer, ok := c.Backend.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// queryKeyer is implemented by backends whose queries depend on other fields
+// of a rule than the common query fields, such as its job and group
+type queryKeyer interface {
+	queryKey(rule structs.Rule) string
+}
+
+// queryKey identifies the effective query of a rule on b. The job and group,
+// which default to where the rule is defined, only count for backends that
+// query them.
+func queryKey(b structs.Backender, rule structs.Rule) string {
+	rule.BackendInstance = nil
+	if keyer, ok := b.(queryKeyer); ok {
+		return keyer.queryKey(rule)
+	}
+	rule.Job = ""
+	rule.Group = ""
+	return commonQueryKey(rule)
+}
+
+// commonQueryKey ignores the fields of a rule that only decide what to do with the value
+func commonQueryKey(rule structs.Rule) string {
+	rule.Name = ""
+	rule.Comparison = ""
+	rule.ComparisonValue = 0
+	rule.Action = ""
+	rule.ActionValue = 0
+	rule.Period = ""
+	return fullQueryKey(rule)
+}
+
+// fullQueryKey is the key of backends that are passed the whole rule
+func fullQueryKey(rule structs.Rule) string {
+	rule.BackendInstance = nil
+	b, _ := json.Marshal(rule)
+	return string(b)
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	log "github.com/sirupsen/logrus"
 	"github.com/underarmour/libra/structs"
@@ -18,6 +17,13 @@ type CloudWatchConfig struct {
 	Name   string
 	Kind   string
 	Region string
+	// Profile is a named profile from the shared AWS config files
+	Profile string
+	// RoleARN is an IAM role to assume through STS, e.g. in another account
+	RoleARN    string
+	ExternalID string
+	// Endpoint overrides the CloudWatch endpoint, e.g. for a local stand-in
+	Endpoint string
 }
 
 // CloudWatchBackend is a metrics backend
@@ -30,10 +36,17 @@ type CloudWatchBackend struct {
 // NewCloudWatchBackend will create a new CloudWatch Client
 func NewCloudWatchBackend(name string, config CloudWatchConfig) (*CloudWatchBackend, error) {
 	// create the cloudwatch client
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(config.Region),
-	}))
-	svc := cloudwatch.New(sess)
+	sess, serviceConfig, err := newAWSSession(AWSConfig{
+		Region:     config.Region,
+		Profile:    config.Profile,
+		RoleARN:    config.RoleARN,
+		ExternalID: config.ExternalID,
+		Endpoint:   config.Endpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+	svc := cloudwatch.New(sess, serviceConfig)
 
 	backend := &CloudWatchBackend{}
 	backend.Name = name
@@ -90,6 +103,49 @@ func (b *CloudWatchBackend) GetValue(rule structs.Rule) (float64, error) {
 	return *s.Datapoints[len(s.Datapoints)-1].Average, nil
 }
 
+// Check lists metrics to verify credentials and connectivity
+func (b *CloudWatchBackend) Check() error {
+	_, err := b.Connection.ListMetrics(&cloudwatch.ListMetricsInput{})
+	return err
+}
+
+// Publish puts the points in the prefix namespace, with Job, Group and Rule dimensions
+func (b *CloudWatchBackend) Publish(prefix string, points []structs.Point) error {
+	data := make([]*cloudwatch.MetricDatum, 0, len(points))
+	for _, p := range points {
+		dimensions := []*cloudwatch.Dimension{
+			{Name: aws.String("Job"), Value: aws.String(p.Job)},
+			{Name: aws.String("Group"), Value: aws.String(p.Group)},
+		}
+		if p.Rule != "" {
+			dimensions = append(dimensions, &cloudwatch.Dimension{Name: aws.String("Rule"), Value: aws.String(p.Rule)})
+		}
+		data = append(data, &cloudwatch.MetricDatum{
+			MetricName: aws.String(p.Name),
+			Dimensions: dimensions,
+			Timestamp:  aws.Time(p.Time),
+			Value:      aws.Float64(p.Value),
+		})
+	}
+
+	// PutMetricData accepts at most 20 metrics per call
+	for len(data) > 0 {
+		n := len(data)
+		if n > 20 {
+			n = 20
+		}
+		_, err := b.Connection.PutMetricData(&cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(prefix),
+			MetricData: data[:n],
+		})
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
 func (b *CloudWatchBackend) Info() *structs.Backend {
 	return &structs.Backend{
 		Kind: b.Config.Kind,
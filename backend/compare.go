@@ -0,0 +1,24 @@
+package backend
+
+import "github.com/underarmour/libra/structs"
+
+// Triggered reports whether value meets the rule's comparison
+func Triggered(r *structs.Rule, value float64) bool {
+	compValue := r.ComparisonValue
+
+	switch r.Comparison {
+	case "above":
+		return value > compValue
+	case "below":
+		return value < compValue
+	case "equal":
+		return value == compValue
+	case "not_equal":
+		return value != compValue
+	case "above_or_equal":
+		return value >= compValue
+	case "below_or_equal":
+		return value <= compValue
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// ConsulConfig is the configuration for a Consul backend
+type ConsulConfig struct {
+	Name       string
+	Kind       string
+	Address    string
+	Datacenter string
+	Token      string
+}
+
+// ConsulBackend is a metrics backend reading service health or KV values from Consul
+type ConsulBackend struct {
+	Name       string
+	Config     ConsulConfig
+	Connection *consul.Client
+}
+
+// NewConsulBackend will create a new Consul Client
+func NewConsulBackend(name string, config ConsulConfig) (*ConsulBackend, error) {
+	// the default config reads CONSUL_HTTP_ADDR, CONSUL_HTTP_TOKEN and friends
+	consulConfig := consul.DefaultConfig()
+	if config.Address != "" {
+		consulConfig.Address = config.Address
+	}
+	if config.Datacenter != "" {
+		consulConfig.Datacenter = config.Datacenter
+	}
+	if config.Token != "" {
+		consulConfig.Token = config.Token
+	}
+
+	client, err := consul.NewClient(consulConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &ConsulBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = client
+
+	return backend, nil
+}
+
+// GetValue returns the number of instances of the rule's service in the health
+// state given by metric_name (passing, warning, critical or total), or the
+// numeric value stored under the rule's key
+func (b *ConsulBackend) GetValue(rule structs.Rule) (float64, error) {
+	if rule.Key != "" {
+		return b.getKV(rule.Key)
+	}
+
+	service := rule.Service
+	if service == "" {
+		return 0.0, fmt.Errorf("Missing service or key inside rule %s", rule.Name)
+	}
+
+	state := rule.MetricName
+	switch state {
+	case consul.HealthPassing, consul.HealthWarning, consul.HealthCritical, "total":
+	default:
+		return 0.0, fmt.Errorf("metric_name must be one of passing, warning, critical or total for rule %s", rule.Name)
+	}
+
+	entries, _, err := b.Connection.Health().Service(service, rule.Tag, false, &consul.QueryOptions{})
+	if err != nil {
+		log.Println(err)
+		return 0.0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if state == "total" || entry.Checks.AggregatedStatus() == state {
+			count++
+		}
+	}
+	return float64(count), nil
+}
+
+func (b *ConsulBackend) getKV(key string) (float64, error) {
+	pair, _, err := b.Connection.KV().Get(key, &consul.QueryOptions{})
+	if err != nil {
+		log.Println(err)
+		return 0.0, err
+	}
+	if pair == nil {
+		return 0.0, errors.New("key " + key + " not found")
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(pair.Value)), 64)
+	if err != nil {
+		return 0.0, fmt.Errorf("value of key %s is not a number: %s", key, err)
+	}
+	return value, nil
+}
+
+// Check asks Consul for its leader
+func (b *ConsulBackend) Check() error {
+	_, err := b.Connection.Status().Leader()
+	return err
+}
+
+func (b *ConsulBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
@@ -0,0 +1,176 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// DefaultDatadogAPIBase is the Datadog API used when none is configured
+const DefaultDatadogAPIBase = "https://api.datadoghq.com"
+
+// DatadogConfig is the configuration for a Datadog backend
+type DatadogConfig struct {
+	Name    string
+	Kind    string
+	APIBase string
+	APIKey  string
+	AppKey  string
+}
+
+// DatadogBackend is a metrics backend using the Datadog metrics query API
+type DatadogBackend struct {
+	Name       string
+	Config     DatadogConfig
+	Connection *http.Client
+}
+
+type datadogQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Series []struct {
+		Metric    string       `json:"metric"`
+		Scope     string       `json:"scope"`
+		Pointlist [][]*float64 `json:"pointlist"`
+	} `json:"series"`
+}
+
+// NewDatadogBackend will create a new Datadog Client
+func NewDatadogBackend(name string, config DatadogConfig) (*DatadogBackend, error) {
+	if config.APIKey == "" || config.AppKey == "" {
+		return nil, errors.New("missing api_key or app_key")
+	}
+	if config.APIBase == "" {
+		config.APIBase = DefaultDatadogAPIBase
+	}
+
+	backend := &DatadogBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = &http.Client{
+		Timeout: time.Second * 10,
+	}
+
+	return backend, nil
+}
+
+// GetValue gets the value of the rule's query
+func (b *DatadogBackend) GetValue(rule structs.Rule) (float64, error) {
+	value, _, err := b.Sample(rule)
+	return value, err
+}
+
+// Sample runs the rule's query over the last 5 minutes (or the rule's from,
+// e.g. "15m"), takes the latest point of every series and aggregates them
+func (b *DatadogBackend) Sample(rule structs.Rule) (float64, []structs.Series, error) {
+	if rule.Query == "" {
+		return 0.0, nil, fmt.Errorf("Missing query inside rule %s", rule.Name)
+	}
+
+	window, err := parseWindow(rule.From, 5*time.Minute)
+	if err != nil {
+		return 0.0, nil, fmt.Errorf("invalid from for rule %s: %s", rule.Name, err)
+	}
+	now := time.Now()
+
+	params := url.Values{}
+	params.Set("query", rule.Query)
+	params.Set("from", strconv.FormatInt(now.Add(-window).Unix(), 10))
+	params.Set("to", strconv.FormatInt(now.Unix(), 10))
+
+	req, err := http.NewRequest("GET", strings.TrimRight(b.Config.APIBase, "/")+"/api/v1/query?"+params.Encode(), nil)
+	if err != nil {
+		return 0.0, nil, err
+	}
+	req.Header.Set("DD-API-KEY", b.Config.APIKey)
+	req.Header.Set("DD-APPLICATION-KEY", b.Config.AppKey)
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		log.Println(err)
+		return 0.0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0.0, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0.0, nil, fmt.Errorf("datadog returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var data datadogQueryResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return 0.0, nil, fmt.Errorf("problem parsing datadog response: %s", err)
+	}
+	if data.Status == "error" || data.Error != "" {
+		return 0.0, nil, fmt.Errorf("datadog query error: %s", data.Error)
+	}
+
+	samples := []structs.Series{}
+	values := []float64{}
+	for _, s := range data.Series {
+		sample := structs.Series{Name: s.Metric + "{" + s.Scope + "}", Values: []*float64{}}
+		for _, p := range s.Pointlist {
+			if len(p) == 2 {
+				sample.Values = append(sample.Values, p[1])
+			}
+		}
+		samples = append(samples, sample)
+		for i := len(s.Pointlist) - 1; i >= 0; i-- {
+			if len(s.Pointlist[i]) == 2 && s.Pointlist[i][1] != nil {
+				values = append(values, *s.Pointlist[i][1])
+				break
+			}
+		}
+	}
+	if len(values) == 0 {
+		return 0.0, samples, errors.New("no datapoints found for query")
+	}
+	value, err := Aggregate(rule.Aggregation, values)
+	return value, samples, err
+}
+
+// parseWindow parses a look-back window such as "15m" or "-15m", or returns the default
+func parseWindow(window string, def time.Duration) (time.Duration, error) {
+	if window == "" {
+		return def, nil
+	}
+	return time.ParseDuration(strings.TrimPrefix(window, "-"))
+}
+
+// Check validates the API key
+func (b *DatadogBackend) Check() error {
+	req, err := http.NewRequest("GET", strings.TrimRight(b.Config.APIBase, "/")+"/api/v1/validate", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("DD-API-KEY", b.Config.APIKey)
+	req.Header.Set("DD-APPLICATION-KEY", b.Config.AppKey)
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("datadog returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (b *DatadogBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// ElasticsearchConfig is the configuration for an Elasticsearch or OpenSearch backend
+type ElasticsearchConfig struct {
+	Name     string
+	Kind     string
+	Host     string
+	Username string
+	Password string
+	// Token is sent as an API key and takes precedence over basic auth
+	Token string
+	// Index is the default index pattern of rules
+	Index string
+	// TimestampField is filtered on when a rule sets from, defaults to @timestamp
+	TimestampField string
+}
+
+// ElasticsearchBackend is a metrics backend running count and aggregation queries
+type ElasticsearchBackend struct {
+	Name       string
+	Config     ElasticsearchConfig
+	Connection *http.Client
+}
+
+// NewElasticsearchBackend will create a new Elasticsearch Client
+func NewElasticsearchBackend(name string, config ElasticsearchConfig) (*ElasticsearchBackend, error) {
+	if config.Host == "" {
+		return nil, errors.New("missing host")
+	}
+	if config.TimestampField == "" {
+		config.TimestampField = "@timestamp"
+	}
+
+	backend := &ElasticsearchBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = &http.Client{
+		Timeout: time.Second * 10,
+	}
+
+	return backend, nil
+}
+
+// GetValue counts the documents matching the rule's query (metric_name count,
+// the default), or aggregates the rule's field over them with avg, sum, max or min
+func (b *ElasticsearchBackend) GetValue(rule structs.Rule) (float64, error) {
+	index := rule.Index
+	if index == "" {
+		index = b.Config.Index
+	}
+	if index == "" {
+		return 0.0, fmt.Errorf("Missing index inside rule %s", rule.Name)
+	}
+
+	query, err := b.buildQuery(rule)
+	if err != nil {
+		return 0.0, err
+	}
+
+	metricName := rule.MetricName
+	switch metricName {
+	case "", "count":
+		var resp struct {
+			Count float64 `json:"count"`
+		}
+		if err := b.post(index, "_count", map[string]interface{}{"query": query}, &resp); err != nil {
+			return 0.0, err
+		}
+		return resp.Count, nil
+	case "avg", "sum", "max", "min":
+		if rule.Field == "" {
+			return 0.0, fmt.Errorf("Missing field inside rule %s", rule.Name)
+		}
+		body := map[string]interface{}{
+			"size":  0,
+			"query": query,
+			"aggs": map[string]interface{}{
+				"value": map[string]interface{}{
+					metricName: map[string]interface{}{"field": rule.Field},
+				},
+			},
+		}
+		var resp struct {
+			Aggregations struct {
+				Value struct {
+					Value *float64 `json:"value"`
+				} `json:"value"`
+			} `json:"aggregations"`
+		}
+		if err := b.post(index, "_search", body, &resp); err != nil {
+			return 0.0, err
+		}
+		if resp.Aggregations.Value.Value == nil {
+			return 0.0, errors.New("no documents matched the query")
+		}
+		return *resp.Aggregations.Value.Value, nil
+	default:
+		return 0.0, fmt.Errorf("metric_name must be one of count, avg, sum, max or min for rule %s", rule.Name)
+	}
+}
+
+// buildQuery combines the rule's query, either query DSL JSON or a query
+// string, with a time range when the rule sets from (e.g. "5m")
+func (b *ElasticsearchBackend) buildQuery(rule structs.Rule) (map[string]interface{}, error) {
+	filters := []interface{}{}
+
+	q := strings.TrimSpace(rule.Query)
+	if strings.HasPrefix(q, "{") {
+		var dsl map[string]interface{}
+		if err := json.Unmarshal([]byte(q), &dsl); err != nil {
+			return nil, fmt.Errorf("invalid query for rule %s: %s", rule.Name, err)
+		}
+		filters = append(filters, dsl)
+	} else if q != "" {
+		filters = append(filters, map[string]interface{}{
+			"query_string": map[string]interface{}{"query": q},
+		})
+	}
+
+	if rule.From != "" {
+		from := rule.From
+		if !strings.HasPrefix(from, "now") {
+			from = "now-" + strings.TrimPrefix(from, "-")
+		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{
+				b.Config.TimestampField: map[string]interface{}{"gte": from},
+			},
+		})
+	}
+
+	if len(filters) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{"filter": filters},
+	}, nil
+}
+
+func (b *ElasticsearchBackend) post(index, endpoint string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return b.do("POST", url.PathEscape(index)+"/"+endpoint, payload, out)
+}
+
+func (b *ElasticsearchBackend) do(method, endpoint string, payload []byte, out interface{}) error {
+	req, err := http.NewRequest(method, strings.TrimRight(b.Config.Host, "/")+"/"+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.Config.Token != "" {
+		req.Header.Set("Authorization", "ApiKey "+b.Config.Token)
+	} else if b.Config.Username != "" {
+		req.SetBasicAuth(b.Config.Username, b.Config.Password)
+	}
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", endpoint, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("problem parsing %s response: %s", endpoint, err)
+	}
+	return nil
+}
+
+// Check reads the cluster health
+func (b *ElasticsearchBackend) Check() error {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := b.do("GET", "_cluster/health", nil, &resp); err != nil {
+		return err
+	}
+	if resp.Status == "red" {
+		return errors.New("cluster health is red")
+	}
+	return nil
+}
+
+func (b *ElasticsearchBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
@@ -0,0 +1,119 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// DefaultExecTimeout bounds how long a command may run when no timeout is configured
+const DefaultExecTimeout = 10 * time.Second
+
+// ExecConfig is the configuration for an external command backend
+type ExecConfig struct {
+	Name    string
+	Kind    string
+	Command []string
+	Timeout time.Duration
+}
+
+// ExecBackend is a metrics backend that runs a command and parses its output
+type ExecBackend struct {
+	Name   string
+	Config ExecConfig
+}
+
+// NewExecBackend will create a new command backend
+func NewExecBackend(name string, config ExecConfig) (*ExecBackend, error) {
+	if len(config.Command) == 0 {
+		return nil, errors.New("missing command")
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultExecTimeout
+	}
+
+	backend := &ExecBackend{}
+	backend.Name = name
+	backend.Config = config
+
+	return backend, nil
+}
+
+// queryKey is the whole rule, which is passed to the command
+func (b *ExecBackend) queryKey(rule structs.Rule) string {
+	return fullQueryKey(rule)
+}
+
+// GetValue runs the command with the rule passed as LIBRA_* environment variables.
+// Stdout must be a single number, or JSON from which the rule's expression extracts one.
+func (b *ExecBackend) GetValue(rule structs.Rule) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, b.Config.Command[0], b.Config.Command[1:]...)
+	cmd.Env = append(os.Environ(), ruleEnv(rule)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0.0, fmt.Errorf("command %s timed out after %s", b.Config.Command[0], b.Config.Timeout)
+		}
+		log.Errorf("command %s failed: %s", b.Config.Command[0], strings.TrimSpace(stderr.String()))
+		return 0.0, err
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return 0.0, errors.New("command produced no output")
+	}
+	if value, err := strconv.ParseFloat(output, 64); err == nil {
+		return value, nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return 0.0, fmt.Errorf("command output is neither a number nor JSON: %s", err)
+	}
+	if rule.Expression == "" {
+		return 0.0, fmt.Errorf("Missing expression inside rule %s to extract a value from JSON output", rule.Name)
+	}
+	return ExtractValue(rule.Expression, data, rule.Aggregation)
+}
+
+// ruleEnv exposes the rule fields to the command
+func ruleEnv(rule structs.Rule) []string {
+	return []string{
+		"LIBRA_RULE=" + rule.Name,
+		"LIBRA_JOB=" + rule.Job,
+		"LIBRA_GROUP=" + rule.Group,
+		"LIBRA_BACKEND=" + rule.Backend,
+		"LIBRA_METRIC_NAME=" + rule.MetricName,
+		"LIBRA_METRIC_NAMESPACE=" + rule.MetricNamespace,
+		"LIBRA_DIMENSION_NAME=" + rule.DimensionName,
+		"LIBRA_DIMENSION_VALUE=" + rule.DimensionValue,
+		"LIBRA_QUERY=" + rule.Query,
+		"LIBRA_COMPARISON=" + rule.Comparison,
+		"LIBRA_COMPARISON_VALUE=" + strconv.FormatFloat(rule.ComparisonValue, 'f', -1, 64),
+		"LIBRA_ACTION=" + rule.Action,
+		"LIBRA_ACTION_VALUE=" + strconv.Itoa(rule.ActionValue),
+	}
+}
+
+func (b *ExecBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
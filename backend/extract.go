@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// ExtractValue searches decoded JSON data with a JMESPath expression and converts
+// the result to a float. Numeric strings and booleans are accepted, and a list
+// of values is reduced with the given aggregation.
+func ExtractValue(expression string, data interface{}, aggregation string) (float64, error) {
+	result, err := jmespath.Search(expression, data)
+	if err != nil {
+		return 0.0, fmt.Errorf("problem evaluating expression '%s': %s", expression, err)
+	}
+
+	if list, ok := result.([]interface{}); ok {
+		values := []float64{}
+		for _, item := range list {
+			v, err := toFloat(item)
+			if err != nil {
+				return 0.0, fmt.Errorf("expression '%s': %s", expression, err)
+			}
+			values = append(values, v)
+		}
+		return Aggregate(aggregation, values)
+	}
+
+	v, err := toFloat(result)
+	if err != nil {
+		return 0.0, fmt.Errorf("expression '%s': %s", expression, err)
+	}
+	return v, nil
+}
+
+// ExtractString searches decoded JSON data with a JMESPath expression that must result in a string
+func ExtractString(expression string, data interface{}) (string, error) {
+	result, err := jmespath.Search(expression, data)
+	if err != nil {
+		return "", fmt.Errorf("problem evaluating expression '%s': %s", expression, err)
+	}
+	s, ok := result.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("expression '%s' did not result in a string", expression)
+	}
+	return s, nil
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	case nil:
+		return 0.0, fmt.Errorf("no value found")
+	default:
+		return 0.0, fmt.Errorf("value %v is not a number", v)
+	}
+}
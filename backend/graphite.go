@@ -3,6 +3,8 @@ package backend
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/underarmour/libra/graphite"
@@ -16,6 +18,10 @@ type GraphiteConfig struct {
 	Host     string
 	Username string
 	Password string
+	// PathPrefix is prepended to /render, defaults to /graphite
+	PathPrefix string
+	// CarbonAddress receives published metrics, defaults to port 2003 of Host
+	CarbonAddress string
 }
 
 // GraphiteBackend is a metrics backend
@@ -23,36 +29,81 @@ type GraphiteBackend struct {
 	Name       string
 	Config     GraphiteConfig
 	Connection *graphite.Client
+	Carbon     *graphite.Carbon
 }
 
 // NewGraphiteBackend will create a new Graphite Client
 func NewGraphiteBackend(name string, config GraphiteConfig) (*GraphiteBackend, error) {
 	sess := graphite.NewClient(config.Host, config.Username, config.Password)
+	if config.PathPrefix != "" {
+		sess.PathPrefix = config.PathPrefix
+	}
+
+	carbonAddress := config.CarbonAddress
+	if carbonAddress == "" {
+		u, err := url.Parse(config.Host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host %s: %s", config.Host, err)
+		}
+		carbonAddress = net.JoinHostPort(u.Hostname(), graphite.DefaultCarbonPort)
+	}
 
 	backend := &GraphiteBackend{}
 	backend.Name = name
 	backend.Config = config
 	backend.Connection = sess
+	backend.Carbon = graphite.NewCarbon(carbonAddress)
 
 	return backend, nil
 }
 
 // GetValue gets a value
 func (b *GraphiteBackend) GetValue(rule structs.Rule) (float64, error) {
+	value, _, err := b.Sample(rule)
+	return value, err
+}
+
+// Sample gets a value and the series it was reduced from
+func (b *GraphiteBackend) Sample(rule structs.Rule) (float64, []structs.Series, error) {
 	metricName := rule.MetricName
 	if metricName == "" {
-		return 0.0, fmt.Errorf("Missing metric_name inside config{} stanza")
+		return 0.0, nil, fmt.Errorf("Missing metric_name inside config{} stanza")
 	}
 
-	s, err := b.Connection.Render(metricName)
+	series, err := b.Connection.Render(metricName, graphite.RenderOptions{
+		From:          rule.From,
+		Until:         rule.Until,
+		MaxDataPoints: rule.MaxDataPoints,
+	})
 	if err != nil {
 		log.Println(err)
-		return 0.0, err
+		return 0.0, nil, err
+	}
+
+	// take the latest non-null datapoint of every series, then reduce them to one value
+	samples := []structs.Series{}
+	values := []float64{}
+	for _, s := range series {
+		sample := structs.Series{Name: s.Target, Values: []*float64{}}
+		for _, d := range s.Datapoints {
+			sample.Values = append(sample.Values, d.Value)
+		}
+		samples = append(samples, sample)
+		if v, ok := s.Latest(); ok {
+			values = append(values, v)
+		}
 	}
-	if len(s.Datapoints) == 0 {
-		return 0.0, errors.New("no datapoints found for metric")
+	if len(values) == 0 {
+		return 0.0, samples, errors.New("no datapoints found for metric")
 	}
-	return s.Datapoints[len(s.Datapoints)-1][0], nil
+	value, err := Aggregate(rule.Aggregation, values)
+	return value, samples, err
+}
+
+// Check renders a constant series to verify credentials and connectivity
+func (b *GraphiteBackend) Check() error {
+	_, err := b.Connection.Render("constantLine(1)", graphite.RenderOptions{From: "-5min"})
+	return err
 }
 
 func (b *GraphiteBackend) Info() *structs.Backend {
@@ -61,3 +112,20 @@ func (b *GraphiteBackend) Info() *structs.Backend {
 		Name: b.Name,
 	}
 }
+
+// Publish sends the points to carbon as <prefix>.<job>.<group>.<rule>.<name>
+func (b *GraphiteBackend) Publish(prefix string, points []structs.Point) error {
+	metrics := make([]graphite.Metric, 0, len(points))
+	for _, p := range points {
+		path := prefix + "." + graphite.PathNode(p.Job) + "." + graphite.PathNode(p.Group)
+		if p.Rule != "" {
+			path += "." + graphite.PathNode(p.Rule)
+		}
+		metrics = append(metrics, graphite.Metric{
+			Path:  path + "." + graphite.PathNode(p.Name),
+			Value: p.Value,
+			Time:  p.Time,
+		})
+	}
+	return b.Carbon.Send(metrics)
+}
@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// HTTPConfig is the configuration for a generic HTTP JSON backend
+type HTTPConfig struct {
+	Name string
+	Kind string
+	// URL and Body are templates rendered with the rule, e.g. {{.Job}} or {{.MetricName}}
+	URL      string
+	Method   string
+	Headers  map[string]string
+	Body     string
+	Username string
+	Password string
+	// Token is sent as a bearer token and takes precedence over basic auth
+	Token string
+}
+
+// HTTPBackend is a metrics backend that extracts a value from any JSON endpoint
+type HTTPBackend struct {
+	Name         string
+	Config       HTTPConfig
+	Connection   *http.Client
+	urlTemplate  *template.Template
+	bodyTemplate *template.Template
+}
+
+// NewHTTPBackend will create a new HTTP Client
+func NewHTTPBackend(name string, config HTTPConfig) (*HTTPBackend, error) {
+	if config.URL == "" {
+		return nil, errors.New("missing url")
+	}
+
+	config.Method = strings.ToUpper(config.Method)
+	if config.Method == "" {
+		config.Method = "GET"
+	}
+	if config.Method != "GET" && config.Method != "POST" {
+		return nil, fmt.Errorf("unsupported method '%s', must be GET or POST", config.Method)
+	}
+
+	urlTemplate, err := template.New("url").Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url template: %s", err)
+	}
+	bodyTemplate, err := template.New("body").Parse(config.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %s", err)
+	}
+
+	backend := &HTTPBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = &http.Client{
+		Timeout: time.Second * 10,
+	}
+	backend.urlTemplate = urlTemplate
+	backend.bodyTemplate = bodyTemplate
+
+	return backend, nil
+}
+
+// queryKey is the rendered request, as the templates may use any field of the rule
+func (b *HTTPBackend) queryKey(rule structs.Rule) string {
+	var url, body bytes.Buffer
+	if b.urlTemplate.Execute(&url, rule) != nil || b.bodyTemplate.Execute(&body, rule) != nil {
+		return fullQueryKey(rule)
+	}
+	rule.Job = ""
+	rule.Group = ""
+	return commonQueryKey(rule) + "\n" + url.String() + "\n" + body.String()
+}
+
+// GetValue gets a value
+func (b *HTTPBackend) GetValue(rule structs.Rule) (float64, error) {
+	expression := rule.Expression
+	if expression == "" {
+		return 0.0, fmt.Errorf("Missing expression inside rule %s", rule.Name)
+	}
+
+	var url, body bytes.Buffer
+	if err := b.urlTemplate.Execute(&url, rule); err != nil {
+		return 0.0, fmt.Errorf("problem rendering url: %s", err)
+	}
+	if err := b.bodyTemplate.Execute(&body, rule); err != nil {
+		return 0.0, fmt.Errorf("problem rendering body: %s", err)
+	}
+
+	req, err := http.NewRequest(b.Config.Method, url.String(), &body)
+	if err != nil {
+		log.Errorf("problem creating http request: %s", err)
+		return 0.0, err
+	}
+	for k, v := range b.Config.Headers {
+		req.Header.Set(k, v)
+	}
+	if b.Config.Method == "POST" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.Config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.Config.Token)
+	} else if b.Config.Username != "" {
+		req.SetBasicAuth(b.Config.Username, b.Config.Password)
+	}
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		log.Println(err)
+		return 0.0, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0.0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0.0, fmt.Errorf("%s returned %s", url.String(), resp.Status)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		return 0.0, fmt.Errorf("problem parsing response from %s: %s", url.String(), err)
+	}
+
+	return ExtractValue(expression, data, rule.Aggregation)
+}
+
+func (b *HTTPBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
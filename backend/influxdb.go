@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/influxdb"
+	"github.com/underarmour/libra/structs"
+)
+
+// InfluxDBConfig is the configuration for an InfluxDB backend
+type InfluxDBConfig struct {
+	Name     string
+	Kind     string
+	Host     string
+	Username string
+	Password string
+	Token    string
+	// QueryLanguage is either influxql (default, 1.x /query) or flux (2.x /api/v2/query)
+	QueryLanguage string
+	// Database is used by InfluxQL queries
+	Database string
+	// Org and Bucket are used by Flux queries, the bucket is available to them as the variable `bucket`
+	Org    string
+	Bucket string
+}
+
+// InfluxDBBackend is a metrics backend
+type InfluxDBBackend struct {
+	Name       string
+	Config     InfluxDBConfig
+	Connection *influxdb.Client
+}
+
+// NewInfluxDBBackend will create a new InfluxDB Client
+func NewInfluxDBBackend(name string, config InfluxDBConfig) (*InfluxDBBackend, error) {
+	if config.Host == "" {
+		return nil, errors.New("missing host")
+	}
+
+	switch config.QueryLanguage {
+	case "", "influxql":
+		if config.Database == "" {
+			return nil, errors.New("missing database for influxql queries")
+		}
+	case "flux":
+		if config.Org == "" {
+			return nil, errors.New("missing org for flux queries")
+		}
+	default:
+		return nil, fmt.Errorf("unknown query_language '%s', must be influxql or flux", config.QueryLanguage)
+	}
+
+	sess := influxdb.NewClient(config.Host, config.Username, config.Password, config.Token)
+
+	backend := &InfluxDBBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = sess
+
+	return backend, nil
+}
+
+// GetValue gets a value
+func (b *InfluxDBBackend) GetValue(rule structs.Rule) (float64, error) {
+	value, _, err := b.Sample(rule)
+	return value, err
+}
+
+// Sample gets a value and the series it was reduced from
+func (b *InfluxDBBackend) Sample(rule structs.Rule) (float64, []structs.Series, error) {
+	query := rule.Query
+	if query == "" {
+		return 0.0, nil, fmt.Errorf("Missing query inside rule %s", rule.Name)
+	}
+
+	var series []influxdb.Series
+	var err error
+	if b.Config.QueryLanguage == "flux" {
+		if b.Config.Bucket != "" {
+			query = fmt.Sprintf("bucket = %q\n%s", b.Config.Bucket, query)
+		}
+		series, err = b.Connection.Flux(b.Config.Org, query)
+	} else {
+		series, err = b.Connection.Query(b.Config.Database, query)
+	}
+	if err != nil {
+		log.Println(err)
+		return 0.0, nil, err
+	}
+
+	samples := []structs.Series{}
+	values := []float64{}
+	for _, s := range series {
+		samples = append(samples, structs.Series{Name: s.Key(), Values: s.Values})
+		if v, ok := s.Latest(); ok {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return 0.0, samples, errors.New("no datapoints found for query")
+	}
+	value, err := Aggregate(rule.Aggregation, values)
+	return value, samples, err
+}
+
+// Check pings InfluxDB
+func (b *InfluxDBBackend) Check() error {
+	return b.Connection.Ping()
+}
+
+func (b *InfluxDBBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
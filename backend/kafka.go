@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/kafka"
+	"github.com/underarmour/libra/structs"
+)
+
+// KafkaConfig is the configuration for a Kafka consumer group lag backend
+type KafkaConfig struct {
+	Name    string
+	Kind    string
+	Brokers []string
+	Timeout time.Duration
+}
+
+// KafkaBackend is a metrics backend reporting consumer group lag
+type KafkaBackend struct {
+	Name       string
+	Config     KafkaConfig
+	Connection *kafka.Client
+}
+
+// NewKafkaBackend will create a new Kafka Client
+func NewKafkaBackend(name string, config KafkaConfig) (*KafkaBackend, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("missing brokers")
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second * 10
+	}
+
+	backend := &KafkaBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = kafka.NewClient(config.Brokers, config.Timeout)
+
+	return backend, nil
+}
+
+// GetValue returns the lag of the rule's consumer group
+func (b *KafkaBackend) GetValue(rule structs.Rule) (float64, error) {
+	value, _, err := b.Sample(rule)
+	return value, err
+}
+
+// Sample returns the lag of the rule's consumer group on its topic, summed
+// over all partitions unless the rule sets another aggregation, e.g. max
+func (b *KafkaBackend) Sample(rule structs.Rule) (float64, []structs.Series, error) {
+	if rule.Topic == "" {
+		return 0.0, nil, fmt.Errorf("Missing topic inside rule %s", rule.Name)
+	}
+	if rule.ConsumerGroup == "" {
+		return 0.0, nil, fmt.Errorf("Missing consumer_group inside rule %s", rule.Name)
+	}
+
+	aggregation := rule.Aggregation
+	if aggregation == "" {
+		aggregation = "sum"
+	}
+
+	lags, err := b.Connection.Lag(rule.ConsumerGroup, rule.Topic)
+	if err != nil {
+		log.Println(err)
+		return 0.0, nil, err
+	}
+
+	samples := []structs.Series{}
+	values := []float64{}
+	for _, l := range lags {
+		lag := float64(l.Lag)
+		values = append(values, lag)
+		samples = append(samples, structs.Series{Name: fmt.Sprintf("partition %d", l.Partition), Values: []*float64{&lag}})
+	}
+	value, err := Aggregate(aggregation, values)
+	return value, samples, err
+}
+
+// Check fetches cluster metadata from the brokers
+func (b *KafkaBackend) Check() error {
+	return b.Connection.Ping()
+}
+
+func (b *KafkaBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
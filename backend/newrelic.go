@@ -0,0 +1,187 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// DefaultNewRelicAPIBase is the New Relic NerdGraph API used when none is configured
+const DefaultNewRelicAPIBase = "https://api.newrelic.com"
+
+const newRelicNRQLQuery = `query($accountId: Int!, $nrql: Nrql!) { actor { account(id: $accountId) { nrql(query: $nrql) { results } } } }`
+
+const newRelicAccountQuery = `query($accountId: Int!) { actor { account(id: $accountId) { id } } }`
+
+// NewRelicConfig is the configuration for a New Relic backend
+type NewRelicConfig struct {
+	Name      string
+	Kind      string
+	APIBase   string
+	APIKey    string
+	AccountID int
+}
+
+// NewRelicBackend is a metrics backend running NRQL queries through NerdGraph
+type NewRelicBackend struct {
+	Name       string
+	Config     NewRelicConfig
+	Connection *http.Client
+}
+
+type newRelicResponse struct {
+	Data struct {
+		Actor struct {
+			Account struct {
+				NRQL struct {
+					Results []map[string]interface{} `json:"results"`
+				} `json:"nrql"`
+			} `json:"account"`
+		} `json:"actor"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewNewRelicBackend will create a new New Relic Client
+func NewNewRelicBackend(name string, config NewRelicConfig) (*NewRelicBackend, error) {
+	if config.APIKey == "" {
+		return nil, errors.New("missing api_key")
+	}
+	if config.AccountID == 0 {
+		return nil, errors.New("missing account_id")
+	}
+	if config.APIBase == "" {
+		config.APIBase = DefaultNewRelicAPIBase
+	}
+
+	backend := &NewRelicBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = &http.Client{
+		Timeout: time.Second * 10,
+	}
+
+	return backend, nil
+}
+
+// GetValue runs the rule's NRQL query. The value is read with the rule's
+// expression when set, otherwise each result row must have a single numeric
+// field (facets and timestamps aside) and the rows are aggregated.
+func (b *NewRelicBackend) GetValue(rule structs.Rule) (float64, error) {
+	if rule.Query == "" {
+		return 0.0, fmt.Errorf("Missing query inside rule %s", rule.Name)
+	}
+
+	data, err := b.graphql(newRelicNRQLQuery, map[string]interface{}{
+		"accountId": b.Config.AccountID,
+		"nrql":      rule.Query,
+	})
+	if err != nil {
+		return 0.0, err
+	}
+
+	results := data.Data.Actor.Account.NRQL.Results
+	if rule.Expression != "" {
+		rows := make([]interface{}, len(results))
+		for i, row := range results {
+			rows[i] = row
+		}
+		return ExtractValue(rule.Expression, rows, rule.Aggregation)
+	}
+
+	values := []float64{}
+	for _, row := range results {
+		fields := []string{}
+		for k, v := range row {
+			if _, ok := v.(float64); ok && !newRelicIgnoredField(k) {
+				fields = append(fields, k)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 1 {
+			sort.Strings(fields)
+			return 0.0, fmt.Errorf("query returned several values (%s), set expression in rule %s", strings.Join(fields, ", "), rule.Name)
+		}
+		values = append(values, row[fields[0]].(float64))
+	}
+	if len(values) == 0 {
+		return 0.0, errors.New("no values found for query")
+	}
+	return Aggregate(rule.Aggregation, values)
+}
+
+// Check looks up the configured account
+func (b *NewRelicBackend) Check() error {
+	_, err := b.graphql(newRelicAccountQuery, map[string]interface{}{
+		"accountId": b.Config.AccountID,
+	})
+	return err
+}
+
+func (b *NewRelicBackend) graphql(query string, variables map[string]interface{}) (*newRelicResponse, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(b.Config.APIBase, "/")+"/graphql", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("API-Key", b.Config.APIKey)
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("new relic returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var data newRelicResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("problem parsing new relic response: %s", err)
+	}
+	if len(data.Errors) > 0 {
+		return nil, fmt.Errorf("new relic query error: %s", data.Errors[0].Message)
+	}
+	return &data, nil
+}
+
+func newRelicIgnoredField(name string) bool {
+	switch name {
+	case "beginTimeSeconds", "endTimeSeconds", "timestamp":
+		return true
+	}
+	return false
+}
+
+func (b *NewRelicBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	api "github.com/hashicorp/nomad/api"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/structs"
+)
+
+// NomadMetricConfig is the configuration for a Nomad allocation metrics backend
+type NomadMetricConfig struct {
+	Name    string
+	Kind    string
+	Address string
+}
+
+// NomadMetricBackend is a metrics backend reading allocation resource usage from Nomad
+type NomadMetricBackend struct {
+	Name       string
+	Config     NomadMetricConfig
+	Connection *api.Client
+}
+
+// NewNomadMetricBackend will create a new Nomad Client
+func NewNomadMetricBackend(name string, config NomadMetricConfig) (*NomadMetricBackend, error) {
+	client, err := nomad.NewClient(nomad.Config{Address: config.Address})
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &NomadMetricBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = client
+
+	return backend, nil
+}
+
+// queryKey keeps the job and group, whose allocations are measured
+func (b *NomadMetricBackend) queryKey(rule structs.Rule) string {
+	return commonQueryKey(rule)
+}
+
+// GetValue gets the utilisation of the rule's group
+func (b *NomadMetricBackend) GetValue(rule structs.Rule) (float64, error) {
+	value, _, err := b.Sample(rule)
+	return value, err
+}
+
+// Sample gets the CPU or memory utilisation, as a percentage of the reserved
+// resources, of every running allocation of the rule's group and aggregates them
+func (b *NomadMetricBackend) Sample(rule structs.Rule) (float64, []structs.Series, error) {
+	metricName := rule.MetricName
+	if metricName != "cpu" && metricName != "memory" {
+		return 0.0, nil, fmt.Errorf("metric_name must be cpu or memory for rule %s", rule.Name)
+	}
+
+	stubs, _, err := b.Connection.Jobs().Allocations(rule.Job, false, &api.QueryOptions{})
+	if err != nil {
+		log.Println(err)
+		return 0.0, nil, err
+	}
+
+	samples := []structs.Series{}
+	values := []float64{}
+	for _, stub := range stubs {
+		if stub.TaskGroup != rule.Group || stub.ClientStatus != "running" {
+			continue
+		}
+
+		alloc, _, err := b.Connection.Allocations().Info(stub.ID, &api.QueryOptions{})
+		if err != nil {
+			log.Errorf("problem getting allocation %s: %s", stub.ID, err)
+			continue
+		}
+		usage, err := b.Connection.Allocations().Stats(alloc, &api.QueryOptions{})
+		if err != nil {
+			log.Errorf("problem getting stats for allocation %s: %s", stub.ID, err)
+			continue
+		}
+
+		value, err := utilisation(metricName, alloc.Resources, usage)
+		if err != nil {
+			log.Errorf("problem computing %s utilisation for allocation %s: %s", metricName, stub.ID, err)
+			continue
+		}
+		values = append(values, value)
+		samples = append(samples, structs.Series{Name: stub.Name, Values: []*float64{&value}})
+	}
+
+	if len(values) == 0 {
+		return 0.0, samples, errors.New("no running allocations with stats found for group")
+	}
+	value, err := Aggregate(rule.Aggregation, values)
+	return value, samples, err
+}
+
+// utilisation returns the resource usage of an allocation as a percentage of its reservation
+func utilisation(metricName string, reserved *api.Resources, usage *api.AllocResourceUsage) (float64, error) {
+	if reserved == nil || usage == nil || usage.ResourceUsage == nil {
+		return 0.0, errors.New("missing resources")
+	}
+
+	switch metricName {
+	case "cpu":
+		if reserved.CPU == nil || *reserved.CPU == 0 || usage.ResourceUsage.CpuStats == nil {
+			return 0.0, errors.New("missing cpu resources")
+		}
+		return usage.ResourceUsage.CpuStats.TotalTicks / float64(*reserved.CPU) * 100, nil
+	case "memory":
+		if reserved.MemoryMB == nil || *reserved.MemoryMB == 0 || usage.ResourceUsage.MemoryStats == nil {
+			return 0.0, errors.New("missing memory resources")
+		}
+		return float64(usage.ResourceUsage.MemoryStats.RSS) / float64(*reserved.MemoryMB*1024*1024) * 100, nil
+	default:
+		return 0.0, fmt.Errorf("unknown metric %s", metricName)
+	}
+}
+
+// Check asks Nomad for its leader
+func (b *NomadMetricBackend) Check() error {
+	_, err := b.Connection.Status().Leader()
+	return err
+}
+
+func (b *NomadMetricBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
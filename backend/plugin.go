@@ -0,0 +1,231 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/plugin"
+	"github.com/underarmour/libra/structs"
+)
+
+// DefaultPluginTimeout bounds a plugin call when no timeout is configured
+const DefaultPluginTimeout = 30 * time.Second
+
+var (
+	errPluginTimeout = errors.New("plugin call timed out")
+
+	// pluginKinds are the kinds registered by DiscoverPlugins, as opposed to built-in
+	// backends. It is guarded by factoriesLock.
+	pluginKinds = map[string]bool{}
+)
+
+// PluginConfig is the configuration for a backend served by a plugin binary
+type PluginConfig struct {
+	Name    string
+	Kind    string
+	Path    string
+	Timeout time.Duration
+	// Backend is the whole backend stanza, passed on to the plugin
+	Backend structs.Backend
+}
+
+// PluginBackend is a metrics backend running in a separate process
+type PluginBackend struct {
+	Name   string
+	Config PluginConfig
+
+	lock   sync.Mutex
+	cmd    *exec.Cmd
+	client *rpc.Client
+	stderr io.Closer
+}
+
+// DiscoverPlugins registers a backend kind for every libra-backend-<kind>
+// executable in dir. Plugins cannot replace the built-in backends.
+func DiscoverPlugins(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), plugin.Prefix) || f.Mode()&0111 == 0 {
+			continue
+		}
+		kind := strings.TrimPrefix(f.Name(), plugin.Prefix)
+		if kind == "" {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		factory := func(name string, conf structs.Backend) (structs.Backender, error) {
+			timeout, err := parseTimeout(conf.Timeout)
+			if err != nil {
+				return nil, err
+			}
+			return NewPluginBackend(name, PluginConfig{
+				Kind:    conf.Kind,
+				Name:    conf.Name,
+				Path:    path,
+				Timeout: timeout,
+				Backend: conf,
+			})
+		}
+
+		factoriesLock.Lock()
+		_, registered := factories[kind]
+		known := pluginKinds[kind]
+		if !registered || known {
+			factories[kind] = factory
+			pluginKinds[kind] = true
+		}
+		factoriesLock.Unlock()
+		if registered && !known {
+			log.Warnf("Ignoring plugin %s, %s is a built-in backend", f.Name(), kind)
+		} else if !known {
+			log.Infof("Found backend plugin %s (%s)", kind, path)
+		}
+	}
+	return nil
+}
+
+// NewPluginBackend starts the plugin process and configures it
+func NewPluginBackend(name string, config PluginConfig) (*PluginBackend, error) {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultPluginTimeout
+	}
+
+	backend := &PluginBackend{}
+	backend.Name = name
+	backend.Config = config
+
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if err := backend.start(); err != nil {
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+// start launches the process and sends it the backend configuration. The lock must be held.
+func (b *PluginBackend) start() error {
+	cmd := exec.Command(b.Config.Path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr := log.WithField("plugin", b.Name).Writer()
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		stderr.Close()
+		return fmt.Errorf("problem starting plugin %s: %s", b.Config.Path, err)
+	}
+
+	b.cmd = cmd
+	b.stderr = stderr
+	b.client = rpc.NewClientWithCodec(jsonrpc.NewClientCodec(plugin.Conn{Reader: stdout, Writer: stdin}))
+
+	conf := b.Config.Backend
+	conf.Name = b.Name
+	if err := b.call("Configure", conf, &plugin.Empty{}); err != nil {
+		b.stop()
+		return fmt.Errorf("problem configuring plugin %s: %s", b.Config.Path, err)
+	}
+	return nil
+}
+
+// stop closes the connection and waits for the process to exit. The lock must be held.
+func (b *PluginBackend) stop() error {
+	if b.cmd == nil {
+		return nil
+	}
+	b.client.Close()
+	done := make(chan error, 1)
+	go func() { done <- b.cmd.Wait() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		b.cmd.Process.Kill()
+		err = <-done
+	}
+	b.stderr.Close()
+	b.cmd = nil
+	b.client = nil
+	b.stderr = nil
+	return err
+}
+
+// call makes an RPC call bounded by the configured timeout. The lock must be held.
+func (b *PluginBackend) call(method string, args interface{}, reply interface{}) error {
+	c := b.client.Go(plugin.ServiceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-c.Done:
+		return c.Error
+	case <-time.After(b.Config.Timeout):
+		log.Errorf("Plugin %s did not answer %s within %s", b.Name, method, b.Config.Timeout)
+		return errPluginTimeout
+	}
+}
+
+// queryKey is the whole rule, which is passed to the plugin
+func (b *PluginBackend) queryKey(rule structs.Rule) string {
+	return fullQueryKey(rule)
+}
+
+// GetValue gets a value from the plugin, restarting the process if it has exited
+func (b *PluginBackend) GetValue(rule structs.Rule) (float64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.cmd == nil {
+		if err := b.start(); err != nil {
+			return 0.0, err
+		}
+	}
+
+	var value float64
+	err := b.call("GetValue", rule, &value)
+	if err == rpc.ErrShutdown || err == io.ErrUnexpectedEOF || err == io.EOF || err == errPluginTimeout {
+		log.Errorf("Plugin %s is not responding, restarting it: %s", b.Name, err)
+		b.stop()
+	}
+	if err != nil {
+		return 0.0, err
+	}
+	return value, nil
+}
+
+// Close stops the plugin process
+func (b *PluginBackend) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.stop()
+}
+
+func (b *PluginBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
@@ -0,0 +1,127 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// RabbitMQConfig is the configuration for a RabbitMQ management API backend
+type RabbitMQConfig struct {
+	Name     string
+	Kind     string
+	Host     string
+	Username string
+	Password string
+	// VHost is the default virtual host of queues, defaults to /
+	VHost string
+}
+
+// RabbitMQBackend is a metrics backend reading queue depth from the RabbitMQ management API
+type RabbitMQBackend struct {
+	Name       string
+	Config     RabbitMQConfig
+	Connection *http.Client
+}
+
+// NewRabbitMQBackend will create a new RabbitMQ management API Client
+func NewRabbitMQBackend(name string, config RabbitMQConfig) (*RabbitMQBackend, error) {
+	if config.Host == "" {
+		return nil, errors.New("missing host")
+	}
+	if config.VHost == "" {
+		config.VHost = "/"
+	}
+
+	backend := &RabbitMQBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = &http.Client{
+		Timeout: time.Second * 10,
+	}
+
+	return backend, nil
+}
+
+// GetValue returns a message count of the rule's queue. metric_name selects
+// messages_ready (default), messages_unacknowledged or messages.
+func (b *RabbitMQBackend) GetValue(rule structs.Rule) (float64, error) {
+	queue := rule.Queue
+	if queue == "" {
+		return 0.0, fmt.Errorf("Missing queue inside rule %s", rule.Name)
+	}
+
+	metricName := rule.MetricName
+	switch metricName {
+	case "":
+		metricName = "messages_ready"
+	case "messages_ready", "messages_unacknowledged", "messages":
+	default:
+		return 0.0, fmt.Errorf("metric_name must be one of messages_ready, messages_unacknowledged or messages for rule %s", rule.Name)
+	}
+
+	queueURL := strings.TrimRight(b.Config.Host, "/") + "/api/queues/" + url.PathEscape(b.Config.VHost) + "/" + url.PathEscape(queue)
+	req, err := http.NewRequest("GET", queueURL, nil)
+	if err != nil {
+		return 0.0, err
+	}
+	req.SetBasicAuth(b.Config.Username, b.Config.Password)
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		log.Println(err)
+		return 0.0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0.0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0.0, fmt.Errorf("rabbitmq returned %s for queue %s", resp.Status, queue)
+	}
+
+	var counts map[string]interface{}
+	if err := json.Unmarshal(body, &counts); err != nil {
+		return 0.0, fmt.Errorf("problem parsing rabbitmq response: %s", err)
+	}
+	value, ok := counts[metricName].(float64)
+	if !ok {
+		return 0.0, fmt.Errorf("%s not reported for queue %s", metricName, queue)
+	}
+	return value, nil
+}
+
+// Check reads the cluster overview from the management API
+func (b *RabbitMQBackend) Check() error {
+	req, err := http.NewRequest("GET", strings.TrimRight(b.Config.Host, "/")+"/api/overview", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(b.Config.Username, b.Config.Password)
+
+	resp, err := b.Connection.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rabbitmq returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (b *RabbitMQBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
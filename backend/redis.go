@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// RedisConfig is the configuration for a Redis backend
+type RedisConfig struct {
+	Name     string
+	Kind     string
+	Address  string
+	Password string
+	Database int
+	Timeout  time.Duration
+}
+
+// RedisBackend is a metrics backend reading the length of a Redis key
+type RedisBackend struct {
+	Name   string
+	Config RedisConfig
+}
+
+// NewRedisBackend will create a new Redis backend
+func NewRedisBackend(name string, config RedisConfig) (*RedisBackend, error) {
+	if config.Address == "" {
+		config.Address = "127.0.0.1:6379"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second * 10
+	}
+
+	backend := &RedisBackend{}
+	backend.Name = name
+	backend.Config = config
+
+	return backend, nil
+}
+
+// GetValue returns the length of the rule's key, using the command given by
+// metric_name: llen (default) for lists, xlen for streams or zcard for sorted sets
+func (b *RedisBackend) GetValue(rule structs.Rule) (float64, error) {
+	key := rule.Key
+	if key == "" {
+		return 0.0, fmt.Errorf("Missing key inside rule %s", rule.Name)
+	}
+
+	command := strings.ToUpper(rule.MetricName)
+	switch command {
+	case "":
+		command = "LLEN"
+	case "LLEN", "XLEN", "ZCARD":
+	default:
+		return 0.0, fmt.Errorf("metric_name must be one of llen, xlen or zcard for rule %s", rule.Name)
+	}
+
+	conn, r, err := b.dial()
+	if err != nil {
+		return 0.0, err
+	}
+	defer conn.Close()
+
+	length, err := redisCommand(conn, r, command, key)
+	if err != nil {
+		return 0.0, err
+	}
+	return float64(length), nil
+}
+
+// Check connects, authenticates and sends PING
+func (b *RedisBackend) Check() error {
+	conn, r, err := b.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = redisCommand(conn, r, "PING")
+	return err
+}
+
+// dial opens an authenticated connection to the configured database
+func (b *RedisBackend) dial() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", b.Config.Address, b.Config.Timeout)
+	if err != nil {
+		log.Println(err)
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Now().Add(b.Config.Timeout))
+	r := bufio.NewReader(conn)
+
+	if b.Config.Password != "" {
+		if _, err := redisCommand(conn, r, "AUTH", b.Config.Password); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	if b.Config.Database != 0 {
+		if _, err := redisCommand(conn, r, "SELECT", strconv.Itoa(b.Config.Database)); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return conn, r, nil
+}
+
+// redisCommand sends a command in the RESP protocol and reads a simple string,
+// error or integer reply. Integer replies are returned, other successes return 0.
+func redisCommand(conn net.Conn, r *bufio.Reader, args ...string) (int64, error) {
+	var cmd bytes.Buffer
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write(cmd.Bytes()); err != nil {
+		return 0, err
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return 0, errors.New("empty reply from redis")
+	}
+
+	switch line[0] {
+	case '+':
+		return 0, nil
+	case '-':
+		return 0, fmt.Errorf("redis %s: %s", args[0], line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected reply from redis to %s: %q", args[0], line)
+	}
+}
+
+func (b *RedisBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/underarmour/libra/structs"
+)
+
+type exchange struct {
+	request, reply string
+}
+
+// serveRESP answers each expected request on conn with its reply, failing the
+// test when the bytes sent differ
+func serveRESP(t *testing.T, conn net.Conn, exchanges []exchange) {
+	defer conn.Close()
+	for _, e := range exchanges {
+		req := make([]byte, len(e.request))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			t.Errorf("reading %q: %s", e.request, err)
+			return
+		}
+		if string(req) != e.request {
+			t.Errorf("expected request %q, got %q", e.request, req)
+		}
+		conn.Write([]byte(e.reply))
+	}
+}
+
+func TestRedisCommandReplies(t *testing.T) {
+	cases := []struct {
+		reply string
+		value int64
+		err   string
+	}{
+		{reply: ":42\r\n", value: 42},
+		{reply: ":-1\r\n", value: -1},
+		{reply: "+OK\r\n", value: 0},
+		{reply: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n", err: "redis LLEN: WRONGTYPE Operation against a key holding the wrong kind of value"},
+		{reply: "$2\r\nhi\r\n", err: `unexpected reply from redis to LLEN: "$2"`},
+		{reply: "\r\n", err: "empty reply from redis"},
+		{reply: ":4x\r\n", err: `strconv.ParseInt: parsing "4x": invalid syntax`},
+	}
+	for _, c := range cases {
+		client, server := net.Pipe()
+		go serveRESP(t, server, []exchange{{"*2\r\n$4\r\nLLEN\r\n$4\r\njobs\r\n", c.reply}})
+
+		value, err := redisCommand(client, bufio.NewReader(client), "LLEN", "jobs")
+		client.Close()
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%q: expected error %q, got %v", c.reply, c.err, err)
+			}
+			continue
+		}
+		if err != nil || value != c.value {
+			t.Errorf("%q: expected %d, got %d and %v", c.reply, c.value, value, err)
+		}
+	}
+}
+
+func TestRedisGetValue(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		serveRESP(t, conn, []exchange{
+			{"*2\r\n$4\r\nAUTH\r\n$6\r\nsecret\r\n", "+OK\r\n"},
+			{"*2\r\n$6\r\nSELECT\r\n$1\r\n2\r\n", "+OK\r\n"},
+			{"*2\r\n$4\r\nXLEN\r\n$6\r\nevents\r\n", ":1234\r\n"},
+		})
+	}()
+
+	b, _ := NewRedisBackend("redis", RedisConfig{
+		Address:  l.Addr().String(),
+		Password: "secret",
+		Database: 2,
+		Timeout:  time.Second,
+	})
+	value, err := b.GetValue(structs.Rule{Name: "events", Key: "events", MetricName: "xlen"})
+	if err != nil || value != 1234 {
+		t.Errorf("expected 1234, got %v and %v", value, err)
+	}
+}
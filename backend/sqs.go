@@ -0,0 +1,119 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/structs"
+)
+
+// SQSConfig is the configuration for an SQS backend
+type SQSConfig struct {
+	Name       string
+	Kind       string
+	Region     string
+	Profile    string
+	RoleARN    string
+	ExternalID string
+	Endpoint   string
+}
+
+// SQSBackend is a metrics backend reading queue depth from SQS
+type SQSBackend struct {
+	Name       string
+	Config     SQSConfig
+	Connection *sqs.SQS
+}
+
+// NewSQSBackend will create a new SQS Client
+func NewSQSBackend(name string, config SQSConfig) (*SQSBackend, error) {
+	sess, serviceConfig, err := newAWSSession(AWSConfig{
+		Region:     config.Region,
+		Profile:    config.Profile,
+		RoleARN:    config.RoleARN,
+		ExternalID: config.ExternalID,
+		Endpoint:   config.Endpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &SQSBackend{}
+	backend.Name = name
+	backend.Config = config
+	backend.Connection = sqs.New(sess, serviceConfig)
+
+	return backend, nil
+}
+
+// GetValue returns the approximate number of messages in the rule's queue.
+// metric_name selects visible (default), in_flight or total messages.
+func (b *SQSBackend) GetValue(rule structs.Rule) (float64, error) {
+	queue := rule.Queue
+	if queue == "" {
+		return 0.0, fmt.Errorf("Missing queue inside rule %s", rule.Name)
+	}
+
+	var attributes []string
+	switch rule.MetricName {
+	case "", "visible":
+		attributes = []string{sqs.QueueAttributeNameApproximateNumberOfMessages}
+	case "in_flight":
+		attributes = []string{sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible}
+	case "total":
+		attributes = []string{sqs.QueueAttributeNameApproximateNumberOfMessages, sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible}
+	default:
+		return 0.0, fmt.Errorf("metric_name must be one of visible, in_flight or total for rule %s", rule.Name)
+	}
+
+	// accept either a queue URL or a queue name
+	queueURL := queue
+	if !strings.HasPrefix(queue, "https://") && !strings.HasPrefix(queue, "http://") {
+		out, err := b.Connection.GetQueueUrl(&sqs.GetQueueUrlInput{QueueName: aws.String(queue)})
+		if err != nil {
+			log.Println(err)
+			return 0.0, err
+		}
+		queueURL = *out.QueueUrl
+	}
+
+	out, err := b.Connection.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: aws.StringSlice(attributes),
+	})
+	if err != nil {
+		log.Println(err)
+		return 0.0, err
+	}
+
+	var total float64
+	for _, attribute := range attributes {
+		v, ok := out.Attributes[attribute]
+		if !ok || v == nil {
+			return 0.0, fmt.Errorf("attribute %s missing for queue %s", attribute, queue)
+		}
+		n, err := strconv.ParseFloat(*v, 64)
+		if err != nil {
+			return 0.0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Check lists queues to verify credentials and connectivity
+func (b *SQSBackend) Check() error {
+	_, err := b.Connection.ListQueues(&sqs.ListQueuesInput{})
+	return err
+}
+
+func (b *SQSBackend) Info() *structs.Backend {
+	return &structs.Backend{
+		Kind: b.Config.Kind,
+		Name: b.Name,
+	}
+}
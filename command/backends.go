@@ -0,0 +1,226 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/api"
+)
+
+// maxDatapoints is how many of the latest values of each series backends test prints
+const maxDatapoints = 10
+
+// BackendsCommand is a Command implementation that lists backends or shows the health of one.
+type BackendsCommand struct {
+	Address string
+	Ui      cli.Ui
+}
+
+func (c *BackendsCommand) Help() string {
+	helpText := `
+Usage: libra backends [options] [<name>]
+  List the configured backends, or check the connectivity of a backend and
+  show the outcome of its most recent queries.
+
+  libra backends test <job> <group> <rule> runs the query of a rule once, so
+  a backend named test is checked with -name=test.
+
+Options:
+  -name=<name>     The backend to check, instead of <name>
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *BackendsCommand) Run(args []string) int {
+	backendsFlags := flag.NewFlagSet("backends", flag.ContinueOnError)
+	var name string
+	backendsFlags.StringVar(&c.Address, "addr", "http://127.0.0.1:8646", "Address of a Libra server")
+	backendsFlags.StringVar(&name, "name", "", "The backend to check")
+	if err := backendsFlags.Parse(args); err != nil {
+		return 1
+	}
+	args = backendsFlags.Args()
+	if len(args) > 1 || (name != "" && len(args) > 0) {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	if len(args) == 1 {
+		name = args[0]
+	}
+	client, err := api.NewClient(&api.Config{Address: c.Address})
+	if err != nil {
+		log.Errorf("Failed to create Libra HTTP client: %s", err)
+		return 1
+	}
+
+	if name == "" {
+		var backends []api.BackendResponse
+		if err := getJSON(client, "/backends", &backends); err != nil {
+			c.Ui.Error("Problem listing backends: " + err.Error())
+			return 1
+		}
+		for _, b := range backends {
+			c.Ui.Output(fmt.Sprintf("%s (%s)", b.Name, b.Kind))
+		}
+		return 0
+	}
+
+	var status api.BackendStatusResponse
+	if err := getJSON(client, "/backends/"+url.PathEscape(name), &status); err != nil {
+		c.Ui.Error("Problem checking backend " + name + ": " + err.Error())
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf("Name         = %s", status.Name))
+	c.Ui.Output(fmt.Sprintf("Kind         = %s", status.Kind))
+	switch {
+	case !status.Check.Supported:
+		c.Ui.Output("Check        = not supported")
+	case status.Check.OK:
+		c.Ui.Output(fmt.Sprintf("Check        = ok (%.0fms)", status.Check.LatencyMs))
+	default:
+		c.Ui.Output(fmt.Sprintf("Check        = failed (%.0fms): %s", status.Check.LatencyMs, status.Check.Error))
+	}
+	c.Ui.Output(fmt.Sprintf("Last success = %s", formatTime(status.Health.LastSuccess)))
+	c.Ui.Output(fmt.Sprintf("Last error   = %s", formatTime(status.Health.LastErrorTime)))
+	if status.Health.LastError != "" {
+		c.Ui.Output(fmt.Sprintf("               %s", status.Health.LastError))
+	}
+	c.Ui.Output(fmt.Sprintf("Latency      = %.0fms", status.Health.LatencyMs))
+	if status.Check.Supported && !status.Check.OK {
+		return 1
+	}
+	return 0
+}
+
+func (c *BackendsCommand) Synopsis() string {
+	return "List backends and check their health"
+}
+
+// BackendsTestCommand is a Command implementation that runs the query of a rule once.
+type BackendsTestCommand struct {
+	Address string
+	Ui      cli.Ui
+}
+
+func (c *BackendsTestCommand) Help() string {
+	helpText := `
+Usage: libra backends test [options] <job> <group> <rule>
+  Run the query of a rule once, bypassing the cache, and show the value it
+  returned, the datapoints it was reduced from when the backend reports them,
+  and the decision the rule would make. Nothing is scaled.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *BackendsTestCommand) Run(args []string) int {
+	testFlags := flag.NewFlagSet("backends test", flag.ContinueOnError)
+	testFlags.StringVar(&c.Address, "addr", "http://127.0.0.1:8646", "Address of a Libra server")
+	if err := testFlags.Parse(args); err != nil {
+		return 1
+	}
+	args = testFlags.Args()
+	if len(args) != 3 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	client, err := api.NewClient(&api.Config{Address: c.Address})
+	if err != nil {
+		log.Errorf("Failed to create Libra HTTP client: %s", err)
+		return 1
+	}
+
+	req := &api.BackendTestRequest{Job: args[0], Group: args[1], Rule: args[2]}
+	resp, err := client.NewRequest("/backends/test", "post", req)
+	if err != nil {
+		c.Ui.Error("Problem testing rule " + args[2] + ": " + err.Error())
+		return 1
+	}
+	var result api.BackendTestResponse
+	if err := decodeResponse(resp, &result); err != nil {
+		c.Ui.Error("Problem testing rule " + args[2] + ": " + err.Error())
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Backend   = %s (%.0fms)", result.Backend, result.LatencyMs))
+	c.Ui.Output(fmt.Sprintf("Value     = %.2f", result.Value))
+	if len(result.Datapoints) > 0 {
+		c.Ui.Output("Datapoints:")
+		for _, s := range result.Datapoints {
+			c.Ui.Output(fmt.Sprintf("  %s = %s", s.Name, formatDatapoints(s.Values)))
+		}
+	}
+	c.Ui.Output(fmt.Sprintf("Threshold = %s %.2f", result.Comparison, result.ComparisonValue))
+	if result.Triggered {
+		c.Ui.Output(fmt.Sprintf("Decision  = %s by %d", result.Action, result.ActionValue))
+	} else {
+		c.Ui.Output("Decision  = do nothing")
+	}
+	return 0
+}
+
+func (c *BackendsTestCommand) Synopsis() string {
+	return "Run the query of a rule once"
+}
+
+// formatDatapoints lists the last maxDatapoints values of a series, oldest first
+func formatDatapoints(values []*float64) string {
+	var b bytes.Buffer
+	if len(values) > maxDatapoints {
+		fmt.Fprintf(&b, "(%d earlier) ", len(values)-maxDatapoints)
+		values = values[len(values)-maxDatapoints:]
+	}
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if v == nil {
+			b.WriteString("null")
+		} else {
+			fmt.Fprintf(&b, "%.2f", *v)
+		}
+	}
+	return b.String()
+}
+
+func getJSON(client *api.Client, path string, out interface{}) error {
+	resp, err := client.NewRequest(path, "get", nil)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, out)
+}
+
+// decodeResponse reads a JSON response, or the error message of a failed request
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Error)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Format(time.RFC3339), time.Since(*t).Truncate(time.Second))
+}
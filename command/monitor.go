@@ -0,0 +1,111 @@
+package command
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/api"
+	"github.com/underarmour/libra/events"
+)
+
+// MonitorCommand is a Command implementation that tails the events of a server.
+type MonitorCommand struct {
+	Address string
+	Ui      cli.Ui
+}
+
+func (c *MonitorCommand) Help() string {
+	helpText := `
+Usage: libra monitor [options]
+  Stream what a Libra server does as it happens: rule evaluations, scaling,
+  limits reached, errors and pauses.
+
+Options:
+  -job=<job>       Only show events of this job
+  -group=<group>   Only show events of this task group
+  -type=<types>    Only show these comma separated event types: scale_up,
+                   scale_down, scale_failed, limit_reached, backend_error,
+                   rule_evaluated, paused and resumed
+  -json            Print the events as JSON lines
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *MonitorCommand) Run(args []string) int {
+	var job, group, types string
+	var asJSON bool
+	monitorFlags := flag.NewFlagSet("monitor", flag.ContinueOnError)
+	monitorFlags.StringVar(&c.Address, "addr", "http://127.0.0.1:8646", "Address of a Libra server")
+	monitorFlags.StringVar(&job, "job", "", "Only show events of this job")
+	monitorFlags.StringVar(&group, "group", "", "Only show events of this task group")
+	monitorFlags.StringVar(&types, "type", "", "Only show these comma separated event types")
+	monitorFlags.BoolVar(&asJSON, "json", false, "Print the events as JSON lines")
+	if err := monitorFlags.Parse(args); err != nil {
+		return 1
+	}
+	if len(monitorFlags.Args()) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	client, err := api.NewClient(&api.Config{Address: c.Address})
+	if err != nil {
+		log.Errorf("Failed to create Libra HTTP client: %s", err)
+		return 1
+	}
+
+	q := url.Values{}
+	for k, v := range map[string]string{"job": job, "group": group, "type": types} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	resp, err := client.NewRequest("/events?"+q.Encode(), "get", nil)
+	if err != nil {
+		c.Ui.Error("Problem connecting to Libra: " + err.Error())
+		return 1
+	}
+	if resp.StatusCode != 200 {
+		err := decodeResponse(resp, nil)
+		c.Ui.Error("Problem streaming events: " + err.Error())
+		return 1
+	}
+	defer resp.Body.Close()
+
+	// only the data lines of the stream matter, the event name is the type of the data
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+		if asJSON {
+			c.Ui.Output(data)
+			continue
+		}
+		var e events.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			c.Ui.Error("Problem decoding event: " + err.Error())
+			continue
+		}
+		c.Ui.Output(fmt.Sprintf("%s [%s] %s", e.Time.Local().Format(time.RFC3339), e.Type, e))
+	}
+	if err := scanner.Err(); err != nil {
+		c.Ui.Error("Problem reading events: " + err.Error())
+		return 1
+	}
+	c.Ui.Error("The server closed the stream")
+	return 1
+}
+
+func (c *MonitorCommand) Synopsis() string {
+	return "Stream the events of a Libra server"
+}
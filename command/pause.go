@@ -0,0 +1,177 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/api"
+)
+
+// PauseCommand is a Command implementation that stops automatic scaling.
+type PauseCommand struct {
+	Address string
+	Ui      cli.Ui
+}
+
+func (c *PauseCommand) Help() string {
+	helpText := `
+Usage: libra pause [options] (-all | <job> [<group>])
+  Stop Libra from scaling a task group, every group of a job, or every job
+  with -all, until resumed or for the given duration. Rules are still
+  evaluated, and libra scale and libra set-capacity still work. Pauses are
+  lost when the server restarts.
+
+  Without arguments, list the pauses in effect.
+
+Options:
+  -for=<duration>   Resume automatically after this long, e.g. 2h
+  -reason=<text>    Why autoscaling is paused
+  -all              Pause every job
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PauseCommand) Run(args []string) int {
+	var duration, reason string
+	var all bool
+	pauseFlags := flag.NewFlagSet("pause", flag.ContinueOnError)
+	pauseFlags.StringVar(&c.Address, "addr", "http://127.0.0.1:8646", "Address of a Libra server")
+	pauseFlags.StringVar(&duration, "for", "", "Resume automatically after this long")
+	pauseFlags.StringVar(&reason, "reason", "", "Why autoscaling is paused")
+	pauseFlags.BoolVar(&all, "all", false, "Pause every job")
+	if err := pauseFlags.Parse(args); err != nil {
+		return 1
+	}
+	args = pauseFlags.Args()
+	if len(args) > 2 || (all && len(args) > 0) {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	client, err := api.NewClient(&api.Config{Address: c.Address})
+	if err != nil {
+		log.Errorf("Failed to create Libra HTTP client: %s", err)
+		return 1
+	}
+
+	if !all && len(args) == 0 {
+		var pauses []api.Pause
+		if err := getJSON(client, "/pauses", &pauses); err != nil {
+			c.Ui.Error("Problem listing pauses: " + err.Error())
+			return 1
+		}
+		if len(pauses) == 0 {
+			c.Ui.Output("Autoscaling is not paused")
+		}
+		for _, p := range pauses {
+			c.Ui.Output(formatPause(p))
+		}
+		return 0
+	}
+
+	req := &api.PauseRequest{Duration: duration, Reason: reason}
+	resp, err := client.NewRequest(pausePath(args)+"/pause", "post", req)
+	if err != nil {
+		c.Ui.Error("Problem pausing autoscaling: " + err.Error())
+		return 1
+	}
+	var pause api.Pause
+	if err := decodeResponse(resp, &pause); err != nil {
+		c.Ui.Error("Problem pausing autoscaling: " + err.Error())
+		return 1
+	}
+	c.Ui.Output(formatPause(pause))
+	return 0
+}
+
+func (c *PauseCommand) Synopsis() string {
+	return "Pause autoscaling of a group, a job or every job"
+}
+
+// ResumeCommand is a Command implementation that lifts pauses.
+type ResumeCommand struct {
+	Address string
+	Ui      cli.Ui
+}
+
+func (c *ResumeCommand) Help() string {
+	helpText := `
+Usage: libra resume [options] (-all | <job> [<group>])
+  Let Libra scale again a task group, a job and its groups, or with -all
+  every job and group.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ResumeCommand) Run(args []string) int {
+	var all bool
+	resumeFlags := flag.NewFlagSet("resume", flag.ContinueOnError)
+	resumeFlags.StringVar(&c.Address, "addr", "http://127.0.0.1:8646", "Address of a Libra server")
+	resumeFlags.BoolVar(&all, "all", false, "Resume every job")
+	if err := resumeFlags.Parse(args); err != nil {
+		return 1
+	}
+	args = resumeFlags.Args()
+	if len(args) > 2 || all == (len(args) > 0) {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	client, err := api.NewClient(&api.Config{Address: c.Address})
+	if err != nil {
+		log.Errorf("Failed to create Libra HTTP client: %s", err)
+		return 1
+	}
+
+	resp, err := client.NewRequest(pausePath(args)+"/resume", "post", struct{}{})
+	if err != nil {
+		c.Ui.Error("Problem resuming autoscaling: " + err.Error())
+		return 1
+	}
+	var pauses []api.Pause
+	if err := decodeResponse(resp, &pauses); err != nil {
+		c.Ui.Error("Problem resuming autoscaling: " + err.Error())
+		return 1
+	}
+	c.Ui.Output("Resumed autoscaling")
+	for _, p := range pauses {
+		c.Ui.Output("Still paused: " + formatPause(p))
+	}
+	return 0
+}
+
+func (c *ResumeCommand) Synopsis() string {
+	return "Resume autoscaling of a group, a job or every job"
+}
+
+// pausePath is the API path of the scope named by the job and group arguments
+func pausePath(args []string) string {
+	path := ""
+	if len(args) > 0 {
+		path += "/jobs/" + url.PathEscape(args[0])
+	}
+	if len(args) > 1 {
+		path += "/groups/" + url.PathEscape(args[1])
+	}
+	return path
+}
+
+func formatPause(p api.Pause) string {
+	scope := "all jobs"
+	if p.Group != "" {
+		scope = p.Job + "/" + p.Group
+	} else if p.Job != "" {
+		scope = "job " + p.Job
+	}
+	s := fmt.Sprintf("Paused %s since %s", scope, p.Since.Local().Format(time.RFC3339))
+	if p.Until != nil {
+		s += fmt.Sprintf(" until %s (%s left)", p.Until.Local().Format(time.RFC3339), time.Until(*p.Until).Truncate(time.Second))
+	}
+	if p.Reason != "" {
+		s += ": " + p.Reason
+	}
+	return s
+}
@@ -1,25 +1,16 @@
 package command
 
 import (
-	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
-	"os"
 	"strings"
 
 	"flag"
 
-	"time"
-
 	"github.com/ant0ine/go-json-rest/rest"
 	"github.com/mitchellh/cli"
 	"github.com/sirupsen/logrus"
 	"github.com/underarmour/libra/api"
-	"github.com/underarmour/libra/backend"
-	"github.com/underarmour/libra/config"
-	"github.com/underarmour/libra/nomad"
-	"github.com/underarmour/libra/structs"
 	"gopkg.in/robfig/cron.v2"
 )
 
@@ -44,7 +35,13 @@ func (c *ServerCommand) Run(args []string) int {
 		return 1
 	}
 
-	os.Setenv("LIBRA_CONFIG_DIR", c.ConfDir)
+	rt, err := api.NewRuntime(c.ConfDir)
+	if err != nil {
+		logrus.Errorf("Problem with the Libra server: %s", err)
+		return 1
+	}
+	defer rt.Close()
+
 	s := rest.NewApi()
 	logger := logrus.New()
 	w := logger.Writer()
@@ -56,7 +53,13 @@ func (c *ServerCommand) Run(args []string) int {
 
 	mw := []rest.Middleware{
 		loggingMw,
-		&rest.ContentTypeCheckerMiddleware{},
+		// SNS posts JSON as text/plain
+		&rest.IfMiddleware{
+			Condition: func(r *rest.Request) bool {
+				return r.URL.Path != "/sns"
+			},
+			IfTrue: &rest.ContentTypeCheckerMiddleware{},
+		},
 		&rest.GzipMiddleware{},
 		&rest.JsonIndentMiddleware{},
 		&rest.PoweredByMiddleware{},
@@ -69,13 +72,29 @@ func (c *ServerCommand) Run(args []string) int {
 
 	s.Use(mw...)
 	router, err := rest.MakeRouter(
-		rest.Post("/scale", api.ScaleHandler),
-		rest.Post("/capacity", api.CapacityHandler),
-		rest.Post("/grafana", api.GrafanaHandler),
-		rest.Get("/backends", api.BackendsHandler),
+		rest.Post("/scale", rt.ScaleHandler),
+		rest.Post("/capacity", rt.CapacityHandler),
+		rest.Post("/grafana", rt.GrafanaHandler),
+		rest.Post("/alertmanager", rt.AlertmanagerHandler),
+		rest.Post("/sns", rt.SNSHandler),
+		rest.Post("/webhooks/:name", rt.WebhookHandler),
+		rest.Get("/backends", rt.BackendsHandler),
+		rest.Post("/backends/test", rt.BackendTestHandler),
+		rest.Get("/backends/:name", rt.BackendHandler),
+		rest.Get("/events", rt.EventsHandler),
+		rest.Get("/jobs", rt.JobsHandler),
+		rest.Get("/jobs/:job", rt.JobHandler),
+		rest.Get("/jobs/:job/groups/:group", rt.GroupHandler),
+		rest.Get("/pauses", rt.PausesHandler),
+		rest.Post("/pause", rt.PauseHandler),
+		rest.Post("/resume", rt.ResumeHandler),
+		rest.Post("/jobs/:job/pause", rt.PauseHandler),
+		rest.Post("/jobs/:job/resume", rt.ResumeHandler),
+		rest.Post("/jobs/:job/groups/:group/pause", rt.PauseHandler),
+		rest.Post("/jobs/:job/groups/:group/resume", rt.ResumeHandler),
 		rest.Get("/ping", api.PingHandler),
 		rest.Get("/", api.HomeHandler),
-		rest.Post("/restart", api.RestartHandler),
+		rest.Post("/restart", rt.RestartHandler),
 	)
 	if err != nil {
 		logrus.Fatal(err)
@@ -83,12 +102,12 @@ func (c *ServerCommand) Run(args []string) int {
 
 	s.SetApp(router)
 
-	cr, _, err := loadRules()
-	cr.Start()
+	cr, _, err := loadRules(rt)
 	if err != nil {
 		logrus.Errorf("Problem with the Libra server: %s", err)
 		return 1
 	}
+	cr.Start()
 
 	err = http.ListenAndServe(":8646", s.MakeHandler())
 	if err != nil {
@@ -102,39 +121,25 @@ func (c *ServerCommand) Synopsis() string {
 	return "Run a Libra server"
 }
 
-func loadRules() (*cron.Cron, []cron.EntryID, error) {
-	config, err := config.NewConfig(os.Getenv("LIBRA_CONFIG_DIR"))
-	if err != nil {
-		logrus.Errorf("Failed to read or parse config file: %s", err)
-	}
-	logrus.Info("Loaded and parsed configuration file")
-	n, err := nomad.NewClient(config.Nomad)
-	if err != nil {
-		log.Fatalf("Failed to create Nomad Client: %s", err)
-	}
-	logrus.Info("Successfully created Nomad Client")
-	dc, err := n.Agent().Datacenter()
+func loadRules(rt *api.Runtime) (*cron.Cron, []cron.EntryID, error) {
+	dc, err := rt.Nomad.Agent().Datacenter()
 	if err != nil {
 		logrus.Fatalf("  Failed to get Nomad DC: %s", err)
 	}
 	logrus.Infof("  -> DC: %s", dc)
-	backends, err := backend.InitializeBackends(config.Backends)
-	if err != nil {
-		logrus.Fatalf("%s", err)
-	}
 
 	logrus.Info("")
-	logrus.Infof("Found %d backends", len(backends))
-	for name, b := range backends {
+	logrus.Infof("Found %d backends", len(rt.Backends))
+	for name, b := range rt.Backends {
 		logrus.Infof("  -> %s (%s)", name, b.Info().Kind)
 	}
 	logrus.Info("")
-	logrus.Infof("Found %d jobs", len(config.Jobs))
+	logrus.Infof("Found %d jobs", len(rt.Config.Jobs))
 
 	cr := cron.New()
 	ids := []cron.EntryID{}
 
-	for _, job := range config.Jobs {
+	for _, job := range rt.Config.Jobs {
 		logrus.Infof("  -> Job: %s", job.Name)
 
 		for _, group := range job.Groups {
@@ -142,29 +147,16 @@ func loadRules() (*cron.Cron, []cron.EntryID, error) {
 			logrus.Infof("      min_count = %d", group.MinCount)
 			logrus.Infof("      max_count = %d", group.MaxCount)
 
-			for name, rule := range group.Rules {
-				cfID, err := cr.AddFunc(rule.Period, createCronFunc(rule, &config.Nomad, job.Name, group.Name, group.MinCount, group.MaxCount))
+			for _, rule := range group.Rules {
+				cfID, err := rt.Schedule(cr, job.Name, group, rule)
 				if err != nil {
 					logrus.Errorf("Problem adding autoscaling rule to cron: %s", err)
 					return cr, ids, err
 				}
 				ids = append(ids, cfID)
 				logrus.Infof("  ----> Rule: %s", rule.Name)
-				if backends[rule.Backend] == nil {
-					return cr, ids, fmt.Errorf("Unknown backend: %s (%s)", rule.Backend, name)
-				}
-
-				rule.BackendInstance = backends[rule.Backend]
 			}
 		}
 	}
 	return cr, ids, nil
 }
-
-func createCronFunc(rule *structs.Rule, nomadConf *nomad.Config, job, group string, min, max int) func() {
-	return func() {
-		n := rand.Intn(10) // offset cron jobs slightly so they don't collide
-		time.Sleep(time.Duration(n) * time.Second)
-		backend.Work(rule, nomadConf, job, group, min, max)
-	}
-}
@@ -0,0 +1,176 @@
+package command
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitchellh/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/api"
+	"github.com/underarmour/libra/events"
+)
+
+// StatusCommand is a Command implementation that shows what a server manages.
+type StatusCommand struct {
+	Address string
+	Ui      cli.Ui
+}
+
+func (c *StatusCommand) Help() string {
+	helpText := `
+Usage: libra status [options] [<job> [<group>]]
+  Show the task groups Libra manages with their limits and current count. With
+  a job, also show the rules of its groups: the last value they read, whether
+  it triggers them, when they run next and the last scaling they asked for.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StatusCommand) Run(args []string) int {
+	statusFlags := flag.NewFlagSet("status", flag.ContinueOnError)
+	statusFlags.StringVar(&c.Address, "addr", "http://127.0.0.1:8646", "Address of a Libra server")
+	if err := statusFlags.Parse(args); err != nil {
+		return 1
+	}
+	args = statusFlags.Args()
+	if len(args) > 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	client, err := api.NewClient(&api.Config{Address: c.Address})
+	if err != nil {
+		log.Errorf("Failed to create Libra HTTP client: %s", err)
+		return 1
+	}
+
+	var groups []api.GroupStatus
+	switch len(args) {
+	case 0:
+		var jobs []api.JobStatus
+		if err := getJSON(client, "/jobs", &jobs); err != nil {
+			c.Ui.Error("Problem getting status: " + err.Error())
+			return 1
+		}
+		for _, job := range jobs {
+			groups = append(groups, job.Groups...)
+		}
+		c.Ui.Output(groupsTable(groups))
+		return 0
+	case 1:
+		var job api.JobStatus
+		if err := getJSON(client, "/jobs/"+url.PathEscape(args[0]), &job); err != nil {
+			c.Ui.Error("Problem getting status of job " + args[0] + ": " + err.Error())
+			return 1
+		}
+		groups = job.Groups
+	case 2:
+		var group api.GroupStatus
+		if err := getJSON(client, "/jobs/"+url.PathEscape(args[0])+"/groups/"+url.PathEscape(args[1]), &group); err != nil {
+			c.Ui.Error("Problem getting status of group " + args[0] + "/" + args[1] + ": " + err.Error())
+			return 1
+		}
+		groups = []api.GroupStatus{group}
+	}
+
+	c.Ui.Output(groupsTable(groups))
+	c.Ui.Output("")
+	c.Ui.Output(rulesTable(groups))
+	return 0
+}
+
+func (c *StatusCommand) Synopsis() string {
+	return "Show the status of jobs, groups and rules"
+}
+
+func groupsTable(groups []api.GroupStatus) string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Job\tGroup\tCount\tMin\tMax\tRules\tPaused")
+	for _, g := range groups {
+		count := "unknown"
+		if g.Count != nil {
+			count = fmt.Sprintf("%d", *g.Count)
+		}
+		paused := "no"
+		if g.Paused != nil {
+			paused = "yes"
+			if g.Paused.Until != nil {
+				paused += ", " + relative(g.Paused.Until)
+			}
+			if g.Paused.Reason != "" {
+				paused += ": " + g.Paused.Reason
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%s\n", g.Job, g.Group, count, g.MinCount, g.MaxCount, len(g.Rules), paused)
+	}
+	w.Flush()
+
+	// why a count is unknown doesn't fit in the table
+	for _, g := range groups {
+		if g.CountError != "" {
+			fmt.Fprintf(&b, "\n%s/%s: %s", g.Job, g.Group, g.CountError)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func rulesTable(groups []api.GroupStatus) string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Group\tRule\tBackend\tValue\tThreshold\tTriggered\tBreaching\tLast Run\tNext Run\tLast Action")
+	for _, g := range groups {
+		for _, r := range g.Rules {
+			value := "-"
+			if r.LastError != "" {
+				value = "error"
+			} else if r.LastValue != nil {
+				value = fmt.Sprintf("%.2f", *r.LastValue)
+			}
+			triggered := "no"
+			if r.Triggered {
+				triggered = "yes"
+			}
+			breaching := "-"
+			if r.BreachingSince != nil {
+				breaching = relative(r.BreachingSince)
+			}
+			action := "-"
+			if r.LastAction != nil {
+				action = fmt.Sprintf("%s %s", r.LastAction.Type, relative(&r.LastAction.Time))
+				if r.LastAction.Type == events.ScaleUp || r.LastAction.Type == events.ScaleDown {
+					action = fmt.Sprintf("%s to %d %s", r.LastAction.Type, r.LastAction.NewCount, relative(&r.LastAction.Time))
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s %.2f\t%s\t%s\t%s\t%s\t%s\n", g.Group, r.Name, r.Backend, value, r.Comparison, r.ComparisonValue,
+				triggered, breaching, relative(r.LastEvaluation), relative(r.NextRun), action)
+		}
+	}
+	w.Flush()
+
+	// errors don't fit in the table
+	for _, g := range groups {
+		for _, r := range g.Rules {
+			if r.LastError != "" {
+				fmt.Fprintf(&b, "\n%s/%s: %s", g.Group, r.Name, r.LastError)
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// relative formats a time as a duration from now
+func relative(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	d := time.Until(*t)
+	if d < 0 {
+		return fmt.Sprintf("%s ago", (-d).Truncate(time.Second))
+	}
+	return fmt.Sprintf("in %s", d.Truncate(time.Second))
+}
@@ -15,12 +15,30 @@ func Commands() map[string]cli.CommandFactory {
 		ErrorWriter: os.Stderr,
 	}
 	return map[string]cli.CommandFactory{
+		"backends": func() (cli.Command, error) {
+			return &command.BackendsCommand{Ui: ui}, nil
+		},
+		"backends test": func() (cli.Command, error) {
+			return &command.BackendsTestCommand{Ui: ui}, nil
+		},
+		"monitor": func() (cli.Command, error) {
+			return &command.MonitorCommand{Ui: ui}, nil
+		},
+		"pause": func() (cli.Command, error) {
+			return &command.PauseCommand{Ui: ui}, nil
+		},
 		"ping": func() (cli.Command, error) {
 			return &command.PingCommand{Ui: ui}, nil
 		},
 		"restart": func() (cli.Command, error) {
 			return &command.RestartCommand{Ui: ui}, nil
 		},
+		"resume": func() (cli.Command, error) {
+			return &command.ResumeCommand{Ui: ui}, nil
+		},
+		"status": func() (cli.Command, error) {
+			return &command.StatusCommand{Ui: ui}, nil
+		},
 		"set-capacity": func() (cli.Command, error) {
 			return &command.SetCapacityCommand{Ui: ui}, nil
 		},
@@ -2,12 +2,15 @@ package config
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/hashicorp/hcl"
 	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/nomad"
 )
 
 // NewConfig will return a Config struct
@@ -61,9 +64,120 @@ func NewConfig(path string) (*RootConfig, error) {
 
 			for ruleName, ruleConfig := range groupConfig.Rules {
 				ruleConfig.Name = ruleName
+				if ruleConfig.Job == "" {
+					ruleConfig.Job = jobName
+				}
+				if ruleConfig.Group == "" {
+					ruleConfig.Group = groupName
+				}
 			}
 		}
 	}
 
+	// nomad backends read allocation stats from the cluster Libra scales by
+	// default, an address set on the backend is used as is
+	for name, b := range out.Backends {
+		if b.Kind == "nomad" && b.Address == "" {
+			b.Address = nomad.Address(out.Nomad)
+			out.Backends[name] = b
+		}
+	}
+
+	for name, policy := range out.GrafanaPolicies {
+		policy.Name = name
+		if policy.Job == "" || policy.Group == "" {
+			return nil, fmt.Errorf("grafana_policy %s: missing job or group", name)
+		}
+		job, ok := out.Jobs[policy.Job]
+		if !ok || job.Groups[policy.Group] == nil {
+			return nil, fmt.Errorf("grafana_policy %s: unknown group %s/%s", name, policy.Job, policy.Group)
+		}
+		switch policy.Aggregation {
+		case "", "avg", "sum", "max", "min":
+		default:
+			return nil, fmt.Errorf("grafana_policy %s: aggregation must be avg, sum, max or min", name)
+		}
+		if policy.MaxActionCount < 0 || policy.MinActionCount < 0 {
+			return nil, fmt.Errorf("grafana_policy %s: max_action_count and min_action_count cannot be negative", name)
+		}
+		if policy.MaxActionCount == 0 && policy.MinActionCount == 0 {
+			return nil, fmt.Errorf("grafana_policy %s: set max_action_count, min_action_count or both", name)
+		}
+		if policy.MaxActionCount > 0 && policy.MinActionCount > 0 && policy.MinThreshold > policy.MaxThreshold {
+			return nil, fmt.Errorf("grafana_policy %s: min_threshold cannot be above max_threshold", name)
+		}
+	}
+
+	for name, alarm := range out.CloudWatchAlarms {
+		alarm.Name = name
+		job, ok := out.Jobs[alarm.Job]
+		if !ok || job.Groups[alarm.Group] == nil {
+			return nil, fmt.Errorf("cloudwatch_alarm %s: unknown group %s/%s", name, alarm.Job, alarm.Group)
+		}
+		if alarm.Action != "increase_count" && alarm.Action != "decrease_count" {
+			return nil, fmt.Errorf("cloudwatch_alarm %s: action must be increase_count or decrease_count", name)
+		}
+		if alarm.ActionValue <= 0 {
+			return nil, fmt.Errorf("cloudwatch_alarm %s: action_value must be positive", name)
+		}
+	}
+
+	for name, webhook := range out.Webhooks {
+		webhook.Name = name
+		if webhook.Secret == "" {
+			return nil, fmt.Errorf("webhook %s: missing secret", name)
+		}
+		if webhook.SignatureHeader == "" {
+			webhook.SignatureHeader = DefaultWebhookSignatureHeader
+		}
+		if webhook.Action != "scale" && webhook.Action != "set_capacity" {
+			return nil, fmt.Errorf("webhook %s: action must be scale or set_capacity", name)
+		}
+		if (webhook.Value == nil) == (webhook.ValueExpression == "") {
+			return nil, fmt.Errorf("webhook %s: set one of value or value_expression", name)
+		}
+		if (webhook.Job == "") == (webhook.JobExpression == "") || (webhook.Group == "") == (webhook.GroupExpression == "") {
+			return nil, fmt.Errorf("webhook %s: set one of job or job_expression, and one of group or group_expression", name)
+		}
+		if webhook.Job != "" && webhook.Group != "" {
+			job, ok := out.Jobs[webhook.Job]
+			if !ok || job.Groups[webhook.Group] == nil {
+				return nil, fmt.Errorf("webhook %s: unknown group %s/%s", name, webhook.Job, webhook.Group)
+			}
+		}
+	}
+
+	for name, notifier := range out.Notifiers {
+		notifier.Name = name
+		switch notifier.Kind {
+		case "slack", "webhook", "grafana":
+			if notifier.URL == "" {
+				return nil, fmt.Errorf("notifier %s: missing url", name)
+			}
+		case "email":
+			if notifier.SMTPAddress == "" || notifier.From == "" || len(notifier.To) == 0 {
+				return nil, fmt.Errorf("notifier %s: email notifiers need smtp_address, from and to", name)
+			}
+		default:
+			return nil, fmt.Errorf("notifier %s: kind must be slack, webhook, email or grafana", name)
+		}
+		for _, e := range notifier.Events {
+			switch e {
+			case events.ScaleUp, events.ScaleDown, events.ScaleFailed, events.LimitReached, events.BackendError, events.Paused, events.Resumed:
+			default:
+				return nil, fmt.Errorf("notifier %s: unknown event %s", name, e)
+			}
+		}
+	}
+
+	if out.Metrics.Prefix == "" {
+		out.Metrics.Prefix = DefaultMetricsPrefix
+	}
+	for _, name := range out.Metrics.Backends {
+		if _, ok := out.Backends[name]; !ok {
+			return nil, fmt.Errorf("metrics: unknown backend %s", name)
+		}
+	}
+
 	return &out, nil
 }
@@ -0,0 +1,19 @@
+package config
+
+// GrafanaPolicy scales a task group from the Grafana alerts that reference it,
+// so that alert messages only carry the policy name rather than limits
+type GrafanaPolicy struct {
+	Name  string
+	Job   string `hcl:"job"`
+	Group string `hcl:"group"`
+	// Aggregation reduces the values of all evalMatches, one of avg (default), sum, max or min
+	Aggregation string `hcl:"aggregation"`
+	// RefID selects the query whose value is used from unified alerting payloads, all values are used by default
+	RefID string `hcl:"ref_id"`
+	// The group is scaled up by MaxActionCount above MaxThreshold and down by
+	// MinActionCount below MinThreshold, a count of 0 disables that direction
+	MaxThreshold   float64 `hcl:"max_threshold,float"`
+	MinThreshold   float64 `hcl:"min_threshold,float"`
+	MaxActionCount int     `hcl:"max_action_count"`
+	MinActionCount int     `hcl:"min_action_count"`
+}
@@ -0,0 +1,11 @@
+package config
+
+// DefaultMetricsPrefix starts the Graphite paths and is the CloudWatch namespace of published metrics
+const DefaultMetricsPrefix = "libra"
+
+// Metrics publishes the value, threshold, desired and actual count of every rule evaluation
+type Metrics struct {
+	// Backends are graphite or cloudwatch backends the metrics are sent to
+	Backends []string `hcl:"backends"`
+	Prefix   string   `hcl:"prefix"`
+}
@@ -0,0 +1,35 @@
+package config
+
+// Notifier sends scaling events to Slack, a webhook, by email or as Grafana annotations
+type Notifier struct {
+	Name string
+	// Kind is slack (an incoming webhook URL), webhook, email or grafana
+	Kind string `hcl:"kind"`
+	// URL, Method and Headers describe the request of slack and webhook
+	// notifiers, URL is the address of Grafana for grafana notifiers
+	URL     string            `hcl:"url"`
+	Method  string            `hcl:"method"`
+	Headers map[string]string `hcl:"headers"`
+	// Template is a text/template rendered with the event: the message of
+	// slack and email notifiers, the body of webhook notifiers
+	Template string `hcl:"template"`
+	// SMTPAddress is the host:port email notifiers send through
+	SMTPAddress string   `hcl:"smtp_address"`
+	Username    string   `hcl:"username"`
+	Password    string   `hcl:"password"`
+	From        string   `hcl:"from"`
+	To          []string `hcl:"to"`
+	// APIKey authenticates to Grafana, DashboardUID and PanelID attach
+	// annotations to a dashboard or panel, and Tags are added to their own
+	APIKey       string   `hcl:"api_key"`
+	DashboardUID string   `hcl:"dashboard_uid"`
+	PanelID      int      `hcl:"panel_id"`
+	Tags         []string `hcl:"tags"`
+	// Jobs and Events restrict the notifications to some jobs and event types, all by default
+	Jobs   []string `hcl:"jobs"`
+	Events []string `hcl:"events"`
+	// RateLimit is the minimum time between two notifications of the same type for a task group
+	RateLimit string `hcl:"rate_limit"`
+	// BackendErrorAfter is how long a backend must keep failing before backend_error is sent
+	BackendErrorAfter string `hcl:"backend_error_after"`
+}
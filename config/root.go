@@ -11,4 +11,17 @@ type RootConfig struct {
 	Jobs     map[string]*nomad.Job      `hcl:"job"`
 	Nomad    nomad.Config               `hcl:"nomad"`
 	Backends map[string]structs.Backend `hcl:"backend"`
+	// PluginDir is searched for libra-backend-<kind> backend plugin binaries
+	PluginDir string `hcl:"plugin_dir"`
+	// GrafanaPolicies are referenced by name from Grafana alerts
+	GrafanaPolicies map[string]*GrafanaPolicy `hcl:"grafana_policy"`
+	// SNS and CloudWatchAlarms map CloudWatch alarm notifications to scaling actions
+	SNS              SNSConfig                   `hcl:"sns"`
+	CloudWatchAlarms map[string]*CloudWatchAlarm `hcl:"cloudwatch_alarm"`
+	// Webhooks are signed endpoints triggering a scaling action
+	Webhooks map[string]*Webhook `hcl:"webhook"`
+	// Notifiers are told about scaling events
+	Notifiers map[string]*Notifier `hcl:"notifier"`
+	// Metrics publishes rule evaluations back to backends
+	Metrics Metrics `hcl:"metrics"`
 }
@@ -0,0 +1,17 @@
+package config
+
+// SNSConfig configures the /sns endpoint, which is disabled until topics are listed
+type SNSConfig struct {
+	// TopicARNs are the topics whose notifications and subscriptions are accepted
+	TopicARNs []string `hcl:"topic_arns"`
+}
+
+// CloudWatchAlarm scales a task group when the CloudWatch alarm of the same name goes into ALARM
+type CloudWatchAlarm struct {
+	Name  string
+	Job   string `hcl:"job"`
+	Group string `hcl:"group"`
+	// Action is increase_count or decrease_count
+	Action      string `hcl:"action"`
+	ActionValue int    `hcl:"action_value"`
+}
@@ -0,0 +1,26 @@
+package config
+
+// DefaultWebhookSignatureHeader carries the HMAC-SHA256 of the request body, as hex optionally prefixed with "sha256="
+const DefaultWebhookSignatureHeader = "X-Libra-Signature"
+
+// Webhook exposes a scaling action on POST /webhooks/<name> to callers holding its secret
+type Webhook struct {
+	Name string
+	// Secret signs request bodies, SignatureHeader defaults to X-Libra-Signature
+	Secret          string `hcl:"secret"`
+	SignatureHeader string `hcl:"signature_header"`
+	// Job and Group are fixed, or read from the payload with JMESPath expressions
+	Job             string `hcl:"job"`
+	Group           string `hcl:"group"`
+	JobExpression   string `hcl:"job_expression"`
+	GroupExpression string `hcl:"group_expression"`
+	// Action is scale (change the count by the value) or set_capacity (set the
+	// count to the value), Value is nil when it is not set
+	Action          string `hcl:"action"`
+	Value           *int   `hcl:"value"`
+	ValueExpression string `hcl:"value_expression"`
+	// Limits on top of the group's min_count and max_count, 0 means no limit
+	MinCount  int `hcl:"min_count"`
+	MaxCount  int `hcl:"max_count"`
+	MaxChange int `hcl:"max_change"`
+}
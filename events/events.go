@@ -0,0 +1,164 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Event types. The configuration is read once when the server starts, so
+// there is no event for reloading it.
+const (
+	ScaleUp      = "scale_up"
+	ScaleDown    = "scale_down"
+	ScaleFailed  = "scale_failed"
+	LimitReached = "limit_reached"
+	BackendError = "backend_error"
+	// RuleEvaluated is published for every value a rule reads, triggered or not
+	RuleEvaluated = "rule_evaluated"
+	// Paused and Resumed change whether a scope is scaled automatically
+	Paused  = "paused"
+	Resumed = "resumed"
+)
+
+// Types are all the event types
+var Types = []string{ScaleUp, ScaleDown, ScaleFailed, LimitReached, BackendError, RuleEvaluated, Paused, Resumed}
+
+// Event is something Libra did or failed to do
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Source is what asked for the change: rule, api, grafana, alertmanager, sns or webhook
+	Source string `json:"source,omitempty"`
+	Job    string `json:"job,omitempty"`
+	Group  string `json:"group,omitempty"`
+	Rule   string `json:"rule,omitempty"`
+	// Value and Threshold are the metric value and comparison value that triggered a rule
+	Backend   string   `json:"backend,omitempty"`
+	Value     *float64 `json:"value,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	// Comparison and Triggered are the comparison of a rule and its outcome, on rule_evaluated
+	Comparison string `json:"comparison,omitempty"`
+	Triggered  bool   `json:"triggered,omitempty"`
+	// OldCount and NewCount are set on scaling events, NewCount is the requested count on limit_reached
+	Amount   int    `json:"amount,omitempty"`
+	OldCount int    `json:"old_count,omitempty"`
+	NewCount int    `json:"new_count,omitempty"`
+	Eval     string `json:"eval,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// FailingSince is when the rule started failing to read its backend, on backend_error
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	// Reason and Until describe a pause, Job and Group are empty when it is global or job-wide
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// String summarises the event in a sentence
+func (e Event) String() string {
+	target := e.Job + "/" + e.Group
+	// Rule names the rule, policy, alarm or webhook of the source
+	by := e.Source
+	if e.Rule != "" {
+		by += " " + e.Rule
+	}
+	switch e.Type {
+	case Paused, Resumed:
+		return e.pauseString()
+	case ScaleUp, ScaleDown:
+		s := fmt.Sprintf("Scaled %s from %d to %d (%s)", target, e.OldCount, e.NewCount, by)
+		if e.Value != nil && e.Threshold != nil {
+			s += fmt.Sprintf(", value %.2f vs threshold %.2f", *e.Value, *e.Threshold)
+		}
+		return s
+	case LimitReached:
+		return fmt.Sprintf("Not scaling %s (%s): %s", target, by, e.Error)
+	case ScaleFailed:
+		return fmt.Sprintf("Failed to scale %s (%s): %s", target, by, e.Error)
+	case RuleEvaluated:
+		outcome := "not triggered"
+		if e.Triggered {
+			outcome = "triggered"
+		}
+		return fmt.Sprintf("Rule %s of %s read %.2f (%s %.2f: %s)", e.Rule, target, *e.Value, e.Comparison, *e.Threshold, outcome)
+	case BackendError:
+		s := fmt.Sprintf("Backend %s failed for %s", e.Backend, by)
+		if e.FailingSince != nil {
+			s += fmt.Sprintf(", failing for %s", e.Time.Sub(*e.FailingSince).Truncate(time.Second))
+		}
+		return s + ": " + e.Error
+	default:
+		return fmt.Sprintf("%s %s: %s", e.Type, target, e.Error)
+	}
+}
+
+func (e Event) pauseString() string {
+	scope := "all jobs"
+	if e.Group != "" {
+		scope = e.Job + "/" + e.Group
+	} else if e.Job != "" {
+		scope = "job " + e.Job
+	}
+	s := "Resumed autoscaling of " + scope
+	if e.Type == Paused {
+		s = "Paused autoscaling of " + scope
+		if e.Until != nil {
+			s += " until " + e.Until.Format(time.RFC3339)
+		}
+	}
+	if e.Reason != "" {
+		s += ": " + e.Reason
+	}
+	return s
+}
+
+// Bus delivers published events to every subscriber
+type Bus struct {
+	lock        sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// Publish sends an event to the subscribers without blocking, the event is
+// dropped for subscribers whose buffer is full
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Warnf("Dropped %s event for a slow subscriber", e.Type)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving published events and a function ending the subscription
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.lock.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.lock.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.lock.Lock()
+			delete(b.subscribers, ch)
+			b.lock.Unlock()
+			close(ch)
+		})
+	}
+}
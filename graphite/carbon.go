@@ -0,0 +1,59 @@
+package graphite
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DefaultCarbonPort is the port of the carbon plaintext protocol
+const DefaultCarbonPort = "2003"
+
+// unsafePathChars are replaced in the nodes of metric paths
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// Metric is a value sent to carbon
+type Metric struct {
+	Path  string
+	Value float64
+	Time  time.Time
+}
+
+// Carbon sends metrics with the plaintext protocol
+type Carbon struct {
+	Address string
+	Timeout time.Duration
+}
+
+// NewCarbon creates a Carbon client for a host:port address
+func NewCarbon(address string) *Carbon {
+	return &Carbon{
+		Address: address,
+		Timeout: time.Second * 10,
+	}
+}
+
+// Send writes the metrics in one connection
+func (c *Carbon) Send(metrics []Metric) error {
+	var b bytes.Buffer
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "%s %s %d\n", m.Path, strconv.FormatFloat(m.Value, 'f', -1, 64), m.Time.Unix())
+	}
+
+	conn, err := net.DialTimeout("tcp", c.Address, c.Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(c.Timeout))
+	_, err = conn.Write(b.Bytes())
+	return err
+}
+
+// PathNode makes a string safe to use as a node of a metric path
+func PathNode(s string) string {
+	return unsafePathChars.ReplaceAllString(s, "_")
+}
@@ -2,32 +2,76 @@ package graphite
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	"bytes"
-
 	log "github.com/sirupsen/logrus"
 )
 
+// DefaultPathPrefix is the path the render API is served under when none is configured
+const DefaultPathPrefix = "/graphite"
+
 // Client wraps http.Client so the consumer doesn't have to
 type Client struct {
-	HTTP     *http.Client
-	Host     string
-	Username string
-	Password string
+	HTTP       *http.Client
+	Host       string
+	PathPrefix string
+	Username   string
+	Password   string
 }
 
+// RenderOptions are the optional parameters of a render request
+type RenderOptions struct {
+	// From and Until bound the time range, in any format Graphite accepts (e.g. "-5min")
+	From  string
+	Until string
+
+	// MaxDataPoints asks Graphite to consolidate each series to at most this many points
+	MaxDataPoints int
+}
+
+// RenderResponse is a single series returned by the render endpoint
 type RenderResponse struct {
 	Target     string      `json:"target"`
-	Datapoints [][]float64 `json:"datapoints"`
+	Datapoints []Datapoint `json:"datapoints"`
+}
+
+// Datapoint is a [value, timestamp] pair. Value is nil when Graphite has no data for that interval.
+type Datapoint struct {
+	Value     *float64
+	Timestamp int64
 }
 
-// type Datapoint struct {
-// 	Value     float64 `json:""`
-// 	Timestamp int64   `json:""`
-// }
+// UnmarshalJSON decodes the [value, timestamp] array form used by Graphite
+func (d *Datapoint) UnmarshalJSON(b []byte) error {
+	var pair []*float64
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("expected [value, timestamp] datapoint, got %s", string(b))
+	}
+	d.Value = pair[0]
+	if pair[1] != nil {
+		d.Timestamp = int64(*pair[1])
+	}
+	return nil
+}
+
+// Latest returns the most recent non-null value of the series
+func (r RenderResponse) Latest() (float64, bool) {
+	for i := len(r.Datapoints) - 1; i >= 0; i-- {
+		if r.Datapoints[i].Value != nil {
+			return *r.Datapoints[i].Value, true
+		}
+	}
+	return 0.0, false
+}
 
 // NewClient creates a new Graphite client, including a custom net/http client
 func NewClient(url, username, password string) *Client {
@@ -35,16 +79,38 @@ func NewClient(url, username, password string) *Client {
 		HTTP: &http.Client{
 			Timeout: time.Second * 10,
 		},
-		Host:     url,
-		Username: username,
-		Password: password,
+		Host:       url,
+		PathPrefix: DefaultPathPrefix,
+		Username:   username,
+		Password:   password,
 	}
 }
 
 // Render makes a call to the Graphite /render endpoint: https://graphite-api.readthedocs.io/en/latest/api.html
-func (c *Client) Render(metric string) (RenderResponse, error) {
-	var data RenderResponse
-	req, err := http.NewRequest("GET", c.Host+"/graphite/render?target="+metric+"&format=json", nil)
+// and returns every series matched by the target.
+func (c *Client) Render(target string, opts RenderOptions) ([]RenderResponse, error) {
+	var data []RenderResponse
+
+	params := url.Values{}
+	params.Set("target", target)
+	params.Set("format", "json")
+	if opts.From != "" {
+		params.Set("from", opts.From)
+	}
+	if opts.Until != "" {
+		params.Set("until", opts.Until)
+	}
+	if opts.MaxDataPoints > 0 {
+		params.Set("maxDataPoints", strconv.Itoa(opts.MaxDataPoints))
+	}
+
+	renderURL := strings.TrimRight(c.Host, "/")
+	if prefix := strings.Trim(c.PathPrefix, "/"); prefix != "" {
+		renderURL += "/" + prefix
+	}
+	renderURL += "/render"
+
+	req, err := http.NewRequest("GET", renderURL+"?"+params.Encode(), nil)
 	if err != nil {
 		log.Errorf("problem creating graphite request: %s", err)
 		return data, err
@@ -58,16 +124,15 @@ func (c *Client) Render(metric string) (RenderResponse, error) {
 	defer resp.Body.Close()
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
+		log.Errorf("problem reading graphite response: %s", err)
+		return data, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return data, fmt.Errorf("graphite returned %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
 		log.Errorf("problem parsing graphite response: %s", err)
 		return data, err
 	}
-	bFlattened := flattenJSON(b)
-	json.Unmarshal(bFlattened, &data)
 	return data, nil
 }
-
-func flattenJSON(b []byte) []byte {
-	by := bytes.TrimPrefix(b, []byte("["))
-	by = bytes.TrimSuffix(by, []byte("]"))
-	return by
-}
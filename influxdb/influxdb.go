@@ -0,0 +1,269 @@
+package influxdb
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Client wraps http.Client so the consumer doesn't have to
+type Client struct {
+	HTTP     *http.Client
+	Host     string
+	Username string
+	Password string
+	// Token is sent as "Authorization: Token <token>" and takes precedence over basic auth
+	Token string
+}
+
+// Series is a single series of a query result, reduced to its values
+type Series struct {
+	Name   string
+	Tags   map[string]string
+	Values []*float64
+}
+
+// Latest returns the most recent non-null value of the series
+func (s Series) Latest() (float64, bool) {
+	for i := len(s.Values) - 1; i >= 0; i-- {
+		if s.Values[i] != nil {
+			return *s.Values[i], true
+		}
+	}
+	return 0.0, false
+}
+
+// Key identifies the series in line protocol style, its name followed by its sorted tags
+func (s Series) Key() string {
+	keys := []string{}
+	for k := range s.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	key := s.Name
+	for _, k := range keys {
+		key += "," + k + "=" + s.Tags[k]
+	}
+	return key
+}
+
+type queryResponse struct {
+	Results []struct {
+		Series []struct {
+			Name    string            `json:"name"`
+			Tags    map[string]string `json:"tags"`
+			Columns []string          `json:"columns"`
+			Values  [][]interface{}   `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// NewClient creates a new InfluxDB client, including a custom net/http client
+func NewClient(url, username, password, token string) *Client {
+	return &Client{
+		HTTP: &http.Client{
+			Timeout: time.Second * 10,
+		},
+		Host:     url,
+		Username: username,
+		Password: password,
+		Token:    token,
+	}
+}
+
+// Query runs an InfluxQL query against the 1.x /query endpoint: https://docs.influxdata.com/influxdb/v1.8/tools/api/#query-http-endpoint
+// The first non-time column of every returned series is used as its value.
+func (c *Client) Query(database, query string) ([]Series, error) {
+	params := url.Values{}
+	params.Set("db", database)
+	params.Set("q", query)
+	params.Set("epoch", "s")
+
+	req, err := http.NewRequest("GET", strings.TrimRight(c.Host, "/")+"/query?"+params.Encode(), nil)
+	if err != nil {
+		log.Errorf("problem creating influxdb request: %s", err)
+		return nil, err
+	}
+
+	b, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data queryResponse
+	if err := json.Unmarshal(b, &data); err != nil {
+		log.Errorf("problem parsing influxdb response: %s", err)
+		return nil, err
+	}
+	if data.Error != "" {
+		return nil, fmt.Errorf("influxdb query error: %s", data.Error)
+	}
+
+	series := []Series{}
+	for _, result := range data.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("influxdb query error: %s", result.Error)
+		}
+		for _, rs := range result.Series {
+			column := -1
+			for i, name := range rs.Columns {
+				if name != "time" {
+					column = i
+					break
+				}
+			}
+			if column == -1 {
+				continue
+			}
+
+			s := Series{Name: rs.Name, Tags: rs.Tags}
+			for _, row := range rs.Values {
+				var value *float64
+				if column < len(row) {
+					if v, ok := row[column].(float64); ok {
+						value = &v
+					}
+				}
+				s.Values = append(s.Values, value)
+			}
+			series = append(series, s)
+		}
+	}
+	return series, nil
+}
+
+// Flux runs a Flux query against the 2.x /api/v2/query endpoint: https://docs.influxdata.com/influxdb/v2.0/api/#operation/PostQuery
+// Every table of the annotated CSV response becomes a series of its _value column.
+func (c *Client) Flux(org, query string) ([]Series, error) {
+	params := url.Values{}
+	params.Set("org", org)
+
+	req, err := http.NewRequest("POST", strings.TrimRight(c.Host, "/")+"/api/v2/query?"+params.Encode(), bytes.NewBufferString(query))
+	if err != nil {
+		log.Errorf("problem creating influxdb request: %s", err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.flux")
+	req.Header.Set("Accept", "application/csv")
+
+	b, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFluxCSV(b)
+}
+
+// Ping checks that InfluxDB is up, both 1.x and 2.x answer /ping with 204 No Content
+func (c *Client) Ping() error {
+	req, err := http.NewRequest("GET", strings.TrimRight(c.Host, "/")+"/ping", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("influxdb ping returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Token "+c.Token)
+	} else if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		log.Errorf("problem getting influxdb response: %s", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("problem reading influxdb response: %s", err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("influxdb returned %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
+
+// parseFluxCSV reads the _value column of every table in an annotated CSV response
+func parseFluxCSV(b []byte) ([]Series, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+
+	series := []Series{}
+	tables := map[string]int{}
+	valueColumn, tableColumn := -1, -1
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Errorf("problem parsing influxdb response: %s", err)
+			return nil, err
+		}
+		if len(row) == 0 || strings.HasPrefix(row[0], "#") {
+			continue
+		}
+
+		// every table starts with a header row
+		isHeader := false
+		for i, name := range row {
+			if name == "_value" {
+				valueColumn, isHeader = i, true
+			}
+			if name == "table" {
+				tableColumn = i
+			}
+		}
+		if isHeader {
+			tables = map[string]int{}
+			continue
+		}
+		if valueColumn == -1 || valueColumn >= len(row) {
+			continue
+		}
+
+		table := ""
+		if tableColumn != -1 && tableColumn < len(row) {
+			table = row[tableColumn]
+		}
+		i, ok := tables[table]
+		if !ok {
+			i = len(series)
+			tables[table] = i
+			series = append(series, Series{Name: table})
+		}
+
+		var value *float64
+		if v, err := strconv.ParseFloat(row[valueColumn], 64); err == nil {
+			value = &v
+		}
+		series[i].Values = append(series[i].Values, value)
+	}
+	return series, nil
+}
@@ -0,0 +1,101 @@
+package influxdb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fluxResponse is an annotated CSV response of InfluxDB 2.x with two results,
+// the first made of two tables and ending with a null value
+const fluxResponse = "#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string\r\n" +
+	"#group,false,false,true,true,false,false,true,true,true\r\n" +
+	"#default,_result,,,,,,,,\r\n" +
+	",result,table,_start,_stop,_time,_value,_field,_measurement,host\r\n" +
+	",,0,2021-06-01T10:00:00Z,2021-06-01T11:00:00Z,2021-06-01T10:10:00Z,1.5,usage_user,cpu,web-1\r\n" +
+	",,0,2021-06-01T10:00:00Z,2021-06-01T11:00:00Z,2021-06-01T10:20:00Z,2.5,usage_user,cpu,web-1\r\n" +
+	",,1,2021-06-01T10:00:00Z,2021-06-01T11:00:00Z,2021-06-01T10:10:00Z,3,usage_user,cpu,web-2\r\n" +
+	",,1,2021-06-01T10:00:00Z,2021-06-01T11:00:00Z,2021-06-01T10:20:00Z,,usage_user,cpu,web-2\r\n" +
+	"\r\n" +
+	"#datatype,string,long,double\r\n" +
+	"#group,false,false,false\r\n" +
+	"#default,queued,,\r\n" +
+	",result,table,_value\r\n" +
+	",,0,7\r\n" +
+	"\r\n"
+
+func TestParseFluxCSV(t *testing.T) {
+	series, err := parseFluxCSV([]byte(fluxResponse))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name   string
+		values []interface{}
+		latest float64
+	}{
+		{"0", []interface{}{1.5, 2.5}, 2.5},
+		{"1", []interface{}{3.0, nil}, 3},
+		{"0", []interface{}{7.0}, 7},
+	}
+	if len(series) != len(expected) {
+		t.Fatalf("expected %d series, got %d", len(expected), len(series))
+	}
+	for i, e := range expected {
+		s := series[i]
+		if s.Name != e.name || len(s.Values) != len(e.values) {
+			t.Errorf("series %d: expected table %s with %d values, got %s with %d", i, e.name, len(e.values), s.Name, len(s.Values))
+			continue
+		}
+		for j, v := range e.values {
+			if v == nil {
+				if s.Values[j] != nil {
+					t.Errorf("series %d: expected value %d to be null, got %v", i, j, *s.Values[j])
+				}
+			} else if s.Values[j] == nil || *s.Values[j] != v.(float64) {
+				t.Errorf("series %d: expected value %d to be %v, got %v", i, j, v, s.Values[j])
+			}
+		}
+		if latest, ok := s.Latest(); !ok || latest != e.latest {
+			t.Errorf("series %d: expected latest %v, got %v", i, e.latest, latest)
+		}
+	}
+}
+
+func TestParseFluxCSVEmpty(t *testing.T) {
+	series, err := parseFluxCSV([]byte("\r\n"))
+	if err != nil || len(series) != 0 {
+		t.Errorf("expected no series, got %v and %v", series, err)
+	}
+}
+
+func TestParseFluxCSVMalformed(t *testing.T) {
+	if _, err := parseFluxCSV([]byte(",result,table,_value\r\n,,0,\"7\r\n")); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+func TestFlux(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path != "/api/v2/query" || r.URL.Query().Get("org") != "ops" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("Content-Type") != "application/vnd.flux" || r.Header.Get("Authorization") != "Token secret" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if string(body) != `from(bucket: "metrics")` {
+			t.Errorf("unexpected query %q", body)
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Write([]byte(fluxResponse))
+	}))
+	defer server.Close()
+
+	series, err := NewClient(server.URL+"/", "", "", "secret").Flux("ops", `from(bucket: "metrics")`)
+	if err != nil || len(series) != 3 {
+		t.Errorf("expected 3 series, got %v and %v", series, err)
+	}
+}
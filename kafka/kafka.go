@@ -0,0 +1,445 @@
+// Package kafka is a minimal Kafka client implementing just enough of the wire
+// protocol to compute consumer group lag: Metadata v0, ListOffsets v1,
+// GroupCoordinator v0 and OffsetFetch v1.
+package kafka
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	apiListOffsets      = 2
+	apiMetadata         = 3
+	apiOffsetFetch      = 9
+	apiGroupCoordinator = 10
+
+	clientID = "libra"
+
+	// timestamps asking ListOffsets for the high watermark or the log start
+	offsetLatest   = -1
+	offsetEarliest = -2
+)
+
+// Client talks to a Kafka cluster through a list of bootstrap brokers
+type Client struct {
+	Brokers []string
+	Timeout time.Duration
+
+	correlationID int32
+}
+
+// PartitionLag is the lag of a consumer group on one partition
+type PartitionLag struct {
+	Partition     int32
+	HighWatermark int64
+	// Committed is -1 when the group has not committed an offset for the
+	// partition, its lag is then measured from LogStart, the oldest offset
+	// still in the log
+	Committed int64
+	LogStart  int64
+	Lag       int64
+}
+
+// NewClient creates a new Kafka client
+func NewClient(brokers []string, timeout time.Duration) *Client {
+	return &Client{
+		Brokers: brokers,
+		Timeout: timeout,
+	}
+}
+
+// Lag returns the lag of the consumer group on every partition of the topic
+func (c *Client) Lag(group, topic string) ([]PartitionLag, error) {
+	brokers, leaders, err := c.metadata(topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(leaders) == 0 {
+		return nil, fmt.Errorf("topic %s has no partitions", topic)
+	}
+
+	// high watermarks have to be asked of each partition's leader
+	byLeader := map[int32][]int32{}
+	for partition, leader := range leaders {
+		byLeader[leader] = append(byLeader[leader], partition)
+	}
+	watermarks := map[int32]int64{}
+	partitions := []int32{}
+	for leader, ps := range byLeader {
+		addr, ok := brokers[leader]
+		if !ok {
+			return nil, fmt.Errorf("leader %d of topic %s is not a known broker", leader, topic)
+		}
+		offsets, err := c.listOffsets(addr, topic, ps, offsetLatest)
+		if err != nil {
+			return nil, err
+		}
+		for p, o := range offsets {
+			watermarks[p] = o
+		}
+		partitions = append(partitions, ps...)
+	}
+
+	coordinator, err := c.coordinator(group)
+	if err != nil {
+		return nil, err
+	}
+	committed, err := c.offsetFetch(coordinator, group, topic, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	// a group that never committed on a partition has every message still in
+	// the log left to consume
+	uncommitted := map[int32][]int32{}
+	for _, p := range partitions {
+		if o, ok := committed[p]; !ok || o < 0 {
+			uncommitted[leaders[p]] = append(uncommitted[leaders[p]], p)
+		}
+	}
+	starts := map[int32]int64{}
+	for leader, ps := range uncommitted {
+		offsets, err := c.listOffsets(brokers[leader], topic, ps, offsetEarliest)
+		if err != nil {
+			return nil, err
+		}
+		for p, o := range offsets {
+			starts[p] = o
+		}
+	}
+
+	lags := []PartitionLag{}
+	for _, p := range partitions {
+		l := PartitionLag{
+			Partition:     p,
+			HighWatermark: watermarks[p],
+			Committed:     -1,
+		}
+		if o, ok := committed[p]; ok && o >= 0 {
+			l.Committed = o
+			l.Lag = l.HighWatermark - l.Committed
+		} else {
+			l.LogStart = starts[p]
+			l.Lag = l.HighWatermark - l.LogStart
+		}
+		if l.Lag < 0 {
+			l.Lag = 0
+		}
+		lags = append(lags, l)
+	}
+	return lags, nil
+}
+
+// Ping asks the brokers for the list of brokers in the cluster
+func (c *Client) Ping() error {
+	req := &encoder{}
+	// an empty topic list asks for every topic, only the brokers are read
+	req.arrayLen(0)
+
+	var lastErr error
+	for _, addr := range c.Brokers {
+		d, err := c.request(addr, apiMetadata, 0, req.Bytes())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if n := d.int32(); d.err == nil && n == 0 {
+			return errors.New("cluster reported no brokers")
+		}
+		return d.err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no brokers configured")
+	}
+	return lastErr
+}
+
+// metadata returns the address of every broker and the leader of every partition of the topic
+func (c *Client) metadata(topic string) (map[int32]string, map[int32]int32, error) {
+	req := &encoder{}
+	req.arrayLen(1)
+	req.str(topic)
+
+	var lastErr error
+	for _, addr := range c.Brokers {
+		d, err := c.request(addr, apiMetadata, 0, req.Bytes())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		brokers := map[int32]string{}
+		n := d.int32()
+		for i := int32(0); i < n && d.err == nil; i++ {
+			id := d.int32()
+			host := d.str()
+			port := d.int32()
+			brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		}
+
+		leaders := map[int32]int32{}
+		topics := d.int32()
+		for i := int32(0); i < topics && d.err == nil; i++ {
+			if code := d.int16(); code != 0 {
+				return nil, nil, kafkaError("metadata for topic "+topic, code)
+			}
+			d.str()
+			partitions := d.int32()
+			for j := int32(0); j < partitions && d.err == nil; j++ {
+				code := d.int16()
+				partition := d.int32()
+				leader := d.int32()
+				d.int32Array() // replicas
+				d.int32Array() // isr
+				// 9 is REPLICA_NOT_AVAILABLE, which does not affect the leader
+				if code != 0 && code != 9 {
+					return nil, nil, kafkaError(fmt.Sprintf("metadata for partition %d", partition), code)
+				}
+				leaders[partition] = leader
+			}
+		}
+		if d.err != nil {
+			return nil, nil, d.err
+		}
+		return brokers, leaders, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no brokers configured")
+	}
+	return nil, nil, lastErr
+}
+
+// listOffsets returns the offset of each partition at timestamp, offsetLatest or offsetEarliest
+func (c *Client) listOffsets(addr, topic string, partitions []int32, timestamp int64) (map[int32]int64, error) {
+	req := &encoder{}
+	req.int32(-1) // replica id
+	req.arrayLen(1)
+	req.str(topic)
+	req.arrayLen(len(partitions))
+	for _, p := range partitions {
+		req.int32(p)
+		req.int64(timestamp)
+	}
+
+	d, err := c.request(addr, apiListOffsets, 1, req.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := map[int32]int64{}
+	topics := d.int32()
+	for i := int32(0); i < topics && d.err == nil; i++ {
+		d.str()
+		n := d.int32()
+		for j := int32(0); j < n && d.err == nil; j++ {
+			partition := d.int32()
+			code := d.int16()
+			d.int64() // timestamp
+			offset := d.int64()
+			if code != 0 {
+				return nil, kafkaError(fmt.Sprintf("offsets for partition %d", partition), code)
+			}
+			offsets[partition] = offset
+		}
+	}
+	return offsets, d.err
+}
+
+// coordinator returns the address of the broker coordinating the group
+func (c *Client) coordinator(group string) (string, error) {
+	req := &encoder{}
+	req.str(group)
+
+	var lastErr error
+	for _, addr := range c.Brokers {
+		d, err := c.request(addr, apiGroupCoordinator, 0, req.Bytes())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		code := d.int16()
+		d.int32() // node id
+		host := d.str()
+		port := d.int32()
+		if d.err != nil {
+			return "", d.err
+		}
+		if code != 0 {
+			return "", kafkaError("coordinator for group "+group, code)
+		}
+		return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no brokers configured")
+	}
+	return "", lastErr
+}
+
+// offsetFetch returns the committed offset of the group on each partition
+func (c *Client) offsetFetch(addr, group, topic string, partitions []int32) (map[int32]int64, error) {
+	req := &encoder{}
+	req.str(group)
+	req.arrayLen(1)
+	req.str(topic)
+	req.arrayLen(len(partitions))
+	for _, p := range partitions {
+		req.int32(p)
+	}
+
+	d, err := c.request(addr, apiOffsetFetch, 1, req.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := map[int32]int64{}
+	topics := d.int32()
+	for i := int32(0); i < topics && d.err == nil; i++ {
+		d.str()
+		n := d.int32()
+		for j := int32(0); j < n && d.err == nil; j++ {
+			partition := d.int32()
+			offset := d.int64()
+			d.str() // metadata
+			code := d.int16()
+			if code != 0 {
+				return nil, kafkaError(fmt.Sprintf("committed offset for partition %d", partition), code)
+			}
+			offsets[partition] = offset
+		}
+	}
+	return offsets, d.err
+}
+
+// request sends a single request on a new connection and returns a decoder over the response body
+func (c *Client) request(addr string, apiKey, apiVersion int16, body []byte) (*decoder, error) {
+	conn, err := net.DialTimeout("tcp", addr, c.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	correlationID := atomic.AddInt32(&c.correlationID, 1)
+	header := &encoder{}
+	header.int16(apiKey)
+	header.int16(apiVersion)
+	header.int32(correlationID)
+	header.str(clientID)
+
+	msg := &encoder{}
+	msg.int32(int32(header.Len() + len(body)))
+	msg.Write(header.Bytes())
+	msg.Write(body)
+	if _, err := conn.Write(msg.Bytes()); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	var size int32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size < 4 {
+		return nil, fmt.Errorf("invalid response size %d from %s", size, addr)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(r, resp); err != nil {
+		return nil, err
+	}
+
+	d := &decoder{b: resp}
+	if id := d.int32(); id != correlationID {
+		return nil, fmt.Errorf("unexpected correlation id %d from %s", id, addr)
+	}
+	return d, nil
+}
+
+func kafkaError(context string, code int16) error {
+	return fmt.Errorf("kafka error %d getting %s", code, context)
+}
+
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) int16(v int16) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) int32(v int32) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) int64(v int64) { binary.Write(e, binary.BigEndian, v) }
+
+func (e *encoder) arrayLen(n int) { e.int32(int32(n)) }
+
+func (e *encoder) str(s string) {
+	e.int16(int16(len(s)))
+	e.WriteString(s)
+}
+
+// decoder reads big-endian values, remembering the first error so that callers
+// can check once after a sequence of reads
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.b) < n {
+		d.err = errors.New("kafka response is truncated")
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// str reads a (nullable) string
+func (d *decoder) str() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *decoder) int32Array() []int32 {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	v := []int32{}
+	for i := int32(0); i < n && d.err == nil; i++ {
+		v = append(v, d.int32())
+	}
+	return v
+}
@@ -0,0 +1,156 @@
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Response bodies as sent on the wire, after the correlation id. %s is replaced
+// by the port of the test broker.
+const (
+	// Metadata v0: broker 1 at 127.0.0.1, topic events with partitions 0 and 1 led by broker 1
+	metadataResponse = "00000001" + "00000001" + "0009" + "3132372e302e302e31" + "%s" +
+		"00000001" + "0000" + "0006" + "6576656e7473" + "00000002" +
+		"0000" + "00000000" + "00000001" + "00000001" + "00000001" + "00000001" + "00000001" +
+		"0000" + "00000001" + "00000001" + "00000001" + "00000001" + "00000001" + "00000001"
+	// ListOffsets v1, latest: partition 0 at 1000, partition 1 at 500
+	latestOffsetsResponse = "00000001" + "0006" + "6576656e7473" + "00000002" +
+		"00000000" + "0000" + "ffffffffffffffff" + "00000000000003e8" +
+		"00000001" + "0000" + "ffffffffffffffff" + "00000000000001f4"
+	// ListOffsets v1, earliest: partition 1 starts at 100
+	earliestOffsetsResponse = "00000001" + "0006" + "6576656e7473" + "00000001" +
+		"00000001" + "0000" + "ffffffffffffffff" + "0000000000000064"
+	// GroupCoordinator v0: broker 1 at 127.0.0.1
+	coordinatorResponse = "0000" + "00000001" + "0009" + "3132372e302e302e31" + "%s"
+	// OffsetFetch v1: 900 committed on partition 0, nothing on partition 1
+	offsetFetchResponse = "00000001" + "0006" + "6576656e7473" + "00000002" +
+		"00000000" + "0000000000000384" + "0000" + "0000" +
+		"00000001" + "ffffffffffffffff" + "0000" + "0000"
+	// OffsetFetch v1 with error 25, UNKNOWN_MEMBER_ID, on partition 0
+	offsetFetchErrorResponse = "00000001" + "0006" + "6576656e7473" + "00000001" +
+		"00000000" + "ffffffffffffffff" + "0000" + "0019"
+)
+
+type response struct {
+	apiKey int16
+	body   string
+}
+
+// broker answers requests with the given responses, in order, and fails the
+// test when a request has an unexpected api key
+func broker(t *testing.T, responses []response) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	p, _ := strconv.Atoi(port)
+	portHex := fmt.Sprintf("%08x", p)
+
+	go func() {
+		for _, resp := range responses {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			var size int32
+			binary.Read(r, binary.BigEndian, &size)
+			req := make([]byte, size)
+			io.ReadFull(r, req)
+			if apiKey := int16(binary.BigEndian.Uint16(req)); apiKey != resp.apiKey {
+				t.Errorf("expected api key %d, got %d", resp.apiKey, apiKey)
+			}
+
+			body, err := hex.DecodeString(strings.Replace(resp.body, "%s", portHex, -1))
+			if err != nil {
+				t.Errorf("bad fixture: %s", err)
+			}
+			msg := &encoder{}
+			msg.int32(int32(4 + len(body)))
+			msg.Write(req[4:8]) // correlation id
+			msg.Write(body)
+			conn.Write(msg.Bytes())
+			conn.Close()
+		}
+	}()
+	return l.Addr().String(), func() { l.Close() }
+}
+
+func TestLag(t *testing.T) {
+	addr, stop := broker(t, []response{
+		{apiMetadata, metadataResponse},
+		{apiListOffsets, latestOffsetsResponse},
+		{apiGroupCoordinator, coordinatorResponse},
+		{apiOffsetFetch, offsetFetchResponse},
+		{apiListOffsets, earliestOffsetsResponse},
+	})
+	defer stop()
+
+	lags, err := NewClient([]string{addr}, time.Second).Lag("consumers", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[int32]PartitionLag{
+		0: {Partition: 0, HighWatermark: 1000, Committed: 900, Lag: 100},
+		1: {Partition: 1, HighWatermark: 500, Committed: -1, LogStart: 100, Lag: 400},
+	}
+	if len(lags) != len(expected) {
+		t.Fatalf("expected %d partitions, got %d", len(expected), len(lags))
+	}
+	for _, l := range lags {
+		if l != expected[l.Partition] {
+			t.Errorf("expected %+v, got %+v", expected[l.Partition], l)
+		}
+	}
+}
+
+func TestLagPartitionError(t *testing.T) {
+	addr, stop := broker(t, []response{
+		{apiMetadata, metadataResponse},
+		{apiListOffsets, latestOffsetsResponse},
+		{apiGroupCoordinator, coordinatorResponse},
+		{apiOffsetFetch, offsetFetchErrorResponse},
+	})
+	defer stop()
+
+	_, err := NewClient([]string{addr}, time.Second).Lag("consumers", "events")
+	if err == nil || err.Error() != "kafka error 25 getting committed offset for partition 0" {
+		t.Errorf("expected the partition error, got %v", err)
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	body, _ := hex.DecodeString(strings.Replace(metadataResponse, "%s", "00002384", -1))
+	d := &decoder{b: body[:20]}
+	d.int32()
+	d.int32()
+	if host := d.str(); host != "127.0.0.1" {
+		t.Errorf("expected host 127.0.0.1, got %q", host)
+	}
+	if port := d.int32(); d.err == nil {
+		t.Errorf("expected a truncated response, read port %d", port)
+	}
+	// reads after an error return zero values and keep the first error
+	if v := d.int64(); v != 0 || d.err.Error() != "kafka response is truncated" {
+		t.Errorf("expected 0 and the truncation error, got %d and %v", v, d.err)
+	}
+}
+
+func TestDecoderNullString(t *testing.T) {
+	d := &decoder{b: []byte{0xff, 0xff, 0x00, 0x01}}
+	if s := d.str(); s != "" || d.err != nil {
+		t.Errorf("expected an empty string, got %q and %v", s, d.err)
+	}
+	if n := d.int16(); n != 1 {
+		t.Errorf("expected 1 after the null string, got %d", n)
+	}
+}
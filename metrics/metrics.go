@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+
+	nomadapi "github.com/hashicorp/nomad/api"
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/backend"
+	"github.com/underarmour/libra/config"
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/nomad"
+	"github.com/underarmour/libra/structs"
+)
+
+// Publisher sends the outcome of rule evaluations to backends
+type Publisher struct {
+	Prefix   string
+	Nomad    *nomadapi.Client
+	Backends map[string]structs.Publisher
+}
+
+// NewPublisher creates a Publisher for the backends listed in the configuration
+func NewPublisher(conf config.Metrics, backends backend.ConfiguredBackends, client *nomadapi.Client) (*Publisher, error) {
+	p := &Publisher{
+		Prefix:   conf.Prefix,
+		Nomad:    client,
+		Backends: map[string]structs.Publisher{},
+	}
+	for _, name := range conf.Backends {
+		b, ok := backends[name]
+		if !ok {
+			return nil, fmt.Errorf("metrics: unknown backend %s", name)
+		}
+		if cached, ok := b.(*backend.CachedBackend); ok {
+			b = cached.Unwrap()
+		}
+		publisher, ok := b.(structs.Publisher)
+		if !ok {
+			return nil, fmt.Errorf("metrics: backend %s (%s) cannot publish metrics", name, b.Info().Kind)
+		}
+		p.Backends[name] = publisher
+	}
+	return p, nil
+}
+
+// Points returns the metrics of a rule evaluation: the value and threshold of
+// the rule, and the desired and running counts of its group
+func (p *Publisher) Points(e events.Event) ([]structs.Point, error) {
+	desired, err := nomad.Count(p.Nomad, e.Job, e.Group)
+	if err != nil {
+		return nil, err
+	}
+	running, err := nomad.Running(p.Nomad, e.Job, e.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	point := structs.Point{Time: e.Time, Job: e.Job, Group: e.Group, Rule: e.Rule}
+	points := []structs.Point{}
+	for _, m := range []struct {
+		name  string
+		value float64
+	}{
+		{"value", *e.Value},
+		{"threshold", *e.Threshold},
+		{"desired_count", float64(desired)},
+		{"actual_count", float64(running)},
+	} {
+		point.Name, point.Value = m.name, m.value
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// Run publishes the rule evaluations of the bus until the returned function is called
+func (p *Publisher) Run(bus *events.Bus) func() {
+	ch, stop := bus.Subscribe(100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			if e.Type != events.RuleEvaluated {
+				continue
+			}
+			points, err := p.Points(e)
+			if err != nil {
+				log.Errorf("Problem reading counts of %s/%s for metrics: %s", e.Job, e.Group, err)
+				continue
+			}
+			for name, b := range p.Backends {
+				if err := b.Publish(p.Prefix, points); err != nil {
+					log.Errorf("Problem publishing metrics to backend %s: %s", name, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		stop()
+		<-done
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/underarmour/libra/events"
+	"github.com/underarmour/libra/nomad"
+)
+
+func TestPointsOfSecondGroup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/job/web":
+			fmt.Fprint(w, `{"ID":"web","Name":"web","TaskGroups":[{"Name":"api","Count":3},{"Name":"worker","Count":7}]}`)
+		case "/v1/job/web/summary":
+			fmt.Fprint(w, `{"JobID":"web","Summary":{"api":{"Running":2},"worker":{"Running":6}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := nomad.NewClient(nomad.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Publisher{Prefix: "libra", Nomad: client}
+
+	value, threshold := 91.5, 80.0
+	points, err := p.Points(events.Event{
+		Type:      events.RuleEvaluated,
+		Time:      time.Unix(1519905600, 0),
+		Job:       "web",
+		Group:     "worker",
+		Rule:      "load-high",
+		Value:     &value,
+		Threshold: &threshold,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{"value": 91.5, "threshold": 80, "desired_count": 7, "actual_count": 6}
+	if len(points) != len(expected) {
+		t.Fatalf("expected %d points, got %d", len(expected), len(points))
+	}
+	for _, point := range points {
+		if point.Job != "web" || point.Group != "worker" || point.Rule != "load-high" {
+			t.Errorf("%s: expected web/worker/load-high, got %s/%s/%s", point.Name, point.Job, point.Group, point.Rule)
+		}
+		if v, ok := expected[point.Name]; !ok || point.Value != v {
+			t.Errorf("%s: expected %v, got %v", point.Name, v, point.Value)
+		}
+	}
+}
@@ -2,22 +2,27 @@ package nomad
 
 import (
 	"errors"
+	"fmt"
 	"os"
-	"strconv"
 
 	api "github.com/hashicorp/nomad/api"
 )
 
-// NewClient will create a instance of a nomad API Client
+// RangeError is returned when a new count would be outside of a group's min_count and max_count
+type RangeError struct {
+	Count int
+	Min   int
+	Max   int
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("the new group count (%d) is outside of the configured range (%d-%d)", e.Count, e.Min, e.Max)
+}
+
+// NewClient will create a instance of a nomad API Client for the Address of c
 func NewClient(c Config) (*api.Client, error) {
 	nomadDefaultConfig := api.DefaultConfig()
-	envAddress := os.Getenv("NOMAD_ADDRESS")
-
-	if envAddress != "" {
-		nomadDefaultConfig.Address = envAddress
-	} else {
-		nomadDefaultConfig.Address = c.Address
-	}
+	nomadDefaultConfig.Address = c.Address
 
 	client, err := api.NewClient(nomadDefaultConfig)
 	if err != nil {
@@ -27,19 +32,35 @@ func NewClient(c Config) (*api.Client, error) {
 	return client, nil
 }
 
+// Address returns the address of the cluster Libra scales, NOMAD_ADDRESS
+// takes precedence over the address in the nomad stanza
+func Address(c Config) string {
+	if envAddress := os.Getenv("NOMAD_ADDRESS"); envAddress != "" {
+		return envAddress
+	}
+	return c.Address
+}
+
 // Scale increases or decreases the count of a task group
 func Scale(client *api.Client, jobID, groupID string, scale, min, max int) (string, int, error) {
 	job, _, err := client.Jobs().Info(jobID, &api.QueryOptions{})
 	if err != nil {
 		return "", 0, err
 	}
-	oldCount := *job.TaskGroups[0].Count
+	tg, err := TaskGroup(job, groupID)
+	if err != nil {
+		return "", 0, err
+	}
+	oldCount := *tg.Count
 	newCount := oldCount + scale
 	if newCount < min || newCount > max {
-		return "", oldCount, errors.New("the new group count (" + strconv.Itoa(newCount) + ") is outside of the configured range (" + strconv.Itoa(min) + "-" + strconv.Itoa(max) + ")")
+		return "", oldCount, &RangeError{Count: newCount, Min: min, Max: max}
+	}
+	tg.Count = &newCount
+	resp, _, err := client.Jobs().Register(job, &api.WriteOptions{})
+	if err != nil {
+		return "", oldCount, err
 	}
-	job.TaskGroups[0].Count = &newCount
-	resp, _, _ := client.Jobs().Register(job, &api.WriteOptions{})
 	return resp.EvalID, newCount, nil
 }
 
@@ -72,11 +93,58 @@ func SetCapacity(client *api.Client, jobID, groupID string, count, min, max int)
 	if err != nil {
 		return "", 0, err
 	}
-	oldCount := *job.TaskGroups[0].Count
+	tg, err := TaskGroup(job, groupID)
+	if err != nil {
+		return "", 0, err
+	}
+	oldCount := *tg.Count
 	if count < min || count > max {
-		return "", oldCount, errors.New("the desired count (" + strconv.Itoa(count) + ") is outside of the configured range (" + strconv.Itoa(min) + "-" + strconv.Itoa(max) + ")")
+		return "", oldCount, &RangeError{Count: count, Min: min, Max: max}
+	}
+	tg.Count = &count
+	resp, _, err := client.Jobs().Register(job, &api.WriteOptions{})
+	if err != nil {
+		return "", oldCount, err
 	}
-	job.TaskGroups[0].Count = &count
-	resp, _, _ := client.Jobs().Register(job, &api.WriteOptions{})
 	return resp.EvalID, count, nil
 }
+
+// Count returns the current count of a task group
+func Count(client *api.Client, jobID, groupID string) (int, error) {
+	job, _, err := client.Jobs().Info(jobID, &api.QueryOptions{})
+	if err != nil {
+		return 0, err
+	}
+	tg, err := TaskGroup(job, groupID)
+	if err != nil {
+		return 0, err
+	}
+	return *tg.Count, nil
+}
+
+// TaskGroup finds a task group of a job by name
+func TaskGroup(job *api.Job, groupID string) (*api.TaskGroup, error) {
+	for _, tg := range job.TaskGroups {
+		if tg.Name != nil && *tg.Name == groupID {
+			return tg, nil
+		}
+	}
+	jobID := ""
+	if job.ID != nil {
+		jobID = *job.ID
+	}
+	return nil, fmt.Errorf("could not find task group %s in job %s", groupID, jobID)
+}
+
+// Running returns the number of running allocations of a task group
+func Running(client *api.Client, jobID, groupID string) (int, error) {
+	summary, _, err := client.Jobs().Summary(jobID, &api.QueryOptions{})
+	if err != nil {
+		return 0, err
+	}
+	group, ok := summary.Summary[groupID]
+	if !ok {
+		return 0, fmt.Errorf("job %s has no group %s", jobID, groupID)
+	}
+	return group.Running, nil
+}
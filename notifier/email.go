@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/underarmour/libra/events"
+)
+
+// EmailSender sends notifications through an SMTP server
+type EmailSender struct {
+	Address  string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// NewEmailSender creates an EmailSender, authenticating when a username is set
+func NewEmailSender(address, username, password, from string, to []string) *EmailSender {
+	return &EmailSender{
+		Address:  address,
+		Username: username,
+		Password: password,
+		From:     from,
+		To:       to,
+	}
+}
+
+// Send mails the text, or a summary of the event
+func (s *EmailSender) Send(e events.Event, text string) error {
+	if text == "" {
+		text = e.String()
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Address)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&b, "Subject: [libra] %s %s/%s\r\n", e.Type, e.Job, e.Group)
+	fmt.Fprintf(&b, "Date: %s\r\n", e.Time.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.Replace(text, "\n", "\r\n", -1))
+	return smtp.SendMail(s.Address, auth, s.From, s.To, b.Bytes())
+}
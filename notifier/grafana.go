@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/underarmour/libra/events"
+)
+
+// GrafanaSender posts events as annotations to the Grafana HTTP API
+type GrafanaSender struct {
+	URL          string
+	APIKey       string
+	DashboardUID string
+	PanelID      int
+	Tags         []string
+}
+
+type grafanaAnnotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	PanelID      int      `json:"panelId,omitempty"`
+	Time         int64    `json:"time"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// NewGrafanaSender creates a GrafanaSender for the Grafana at url
+func NewGrafanaSender(url, apiKey, dashboardUID string, panelID int, tags []string) *GrafanaSender {
+	return &GrafanaSender{
+		URL:          strings.TrimSuffix(url, "/"),
+		APIKey:       apiKey,
+		DashboardUID: dashboardUID,
+		PanelID:      panelID,
+		Tags:         tags,
+	}
+}
+
+// Send creates an annotation tagged with the job, group, rule and direction of the event
+func (s *GrafanaSender) Send(e events.Event, text string) error {
+	if text == "" {
+		text = e.String()
+	}
+
+	tags := []string{"libra"}
+	if e.Job != "" {
+		tags = append(tags, "job:"+e.Job)
+	}
+	if e.Group != "" {
+		tags = append(tags, "group:"+e.Group)
+	}
+	if e.Rule != "" {
+		tags = append(tags, "rule:"+e.Rule)
+	}
+	if e.Amount > 0 {
+		tags = append(tags, "direction:up")
+	} else if e.Amount < 0 {
+		tags = append(tags, "direction:down")
+	}
+	tags = append(tags, s.Tags...)
+
+	body, err := json.Marshal(grafanaAnnotation{
+		DashboardUID: s.DashboardUID,
+		PanelID:      s.PanelID,
+		Time:         e.Time.UnixNano() / int64(time.Millisecond),
+		Tags:         tags,
+		Text:         text,
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if s.APIKey != "" {
+		headers["Authorization"] = "Bearer " + s.APIKey
+	}
+	return send("POST", s.URL+"/api/annotations", headers, body)
+}
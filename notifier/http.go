@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/underarmour/libra/events"
+)
+
+var httpClient = &http.Client{
+	Timeout: time.Second * 10,
+}
+
+// SlackSender posts messages to a Slack-compatible incoming webhook
+type SlackSender struct {
+	URL string
+}
+
+// NewSlackSender creates a SlackSender posting to url
+func NewSlackSender(url string) *SlackSender {
+	return &SlackSender{URL: url}
+}
+
+// Send posts the text, or a summary of the event
+func (s *SlackSender) Send(e events.Event, text string) error {
+	if text == "" {
+		text = e.String()
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	return send("POST", s.URL, map[string]string{"Content-Type": "application/json"}, body)
+}
+
+// WebhookSender sends events to an HTTP endpoint
+type WebhookSender struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+}
+
+// NewWebhookSender creates a WebhookSender, method defaults to POST
+func NewWebhookSender(url, method string, headers map[string]string) *WebhookSender {
+	if method == "" {
+		method = "POST"
+	}
+	return &WebhookSender{URL: url, Method: method, Headers: headers}
+}
+
+// Send sends the rendered template when the notifier has one, the event as JSON otherwise
+func (s *WebhookSender) Send(e events.Event, text string) error {
+	headers := map[string]string{"Content-Type": "application/json"}
+	for k, v := range s.Headers {
+		headers[k] = v
+	}
+	body := []byte(text)
+	if text == "" {
+		var err error
+		if body, err = json.Marshal(e); err != nil {
+			return err
+		}
+	}
+	return send(s.Method, s.URL, headers, body)
+}
+
+func send(method, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s returned %s: %s", method, url, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
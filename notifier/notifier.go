@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/underarmour/libra/config"
+	"github.com/underarmour/libra/events"
+)
+
+// DefaultRateLimit is the minimum time between two notifications of the same type for a task group
+const DefaultRateLimit = time.Minute
+
+// DefaultBackendErrorAfter is how long a backend fails before it is reported
+const DefaultBackendErrorAfter = 5 * time.Minute
+
+// Sender delivers a notification, text is the rendered template or empty when the notifier has none
+type Sender interface {
+	Send(e events.Event, text string) error
+}
+
+// Notifier sends the events it is routed to, at most once per rate limit for a type and task group
+type Notifier struct {
+	Name              string
+	Sender            Sender
+	Template          *template.Template
+	Jobs              map[string]bool
+	Events            map[string]bool
+	RateLimit         time.Duration
+	BackendErrorAfter time.Duration
+
+	lock sync.Mutex
+	sent map[string]time.Time
+}
+
+// funcs are available in notifier templates
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewNotifier creates a notifier from its configuration
+func NewNotifier(conf *config.Notifier) (*Notifier, error) {
+	n := &Notifier{
+		Name:              conf.Name,
+		Jobs:              map[string]bool{},
+		Events:            map[string]bool{},
+		RateLimit:         DefaultRateLimit,
+		BackendErrorAfter: DefaultBackendErrorAfter,
+		sent:              map[string]time.Time{},
+	}
+	for _, job := range conf.Jobs {
+		n.Jobs[job] = true
+	}
+	eventTypes := conf.Events
+	if conf.Kind == "grafana" {
+		// annotations mark every change of count unless told otherwise
+		n.RateLimit = 0
+		if len(eventTypes) == 0 {
+			eventTypes = []string{events.ScaleUp, events.ScaleDown}
+		}
+	}
+	for _, e := range eventTypes {
+		n.Events[e] = true
+	}
+
+	var err error
+	if conf.RateLimit != "" {
+		if n.RateLimit, err = time.ParseDuration(conf.RateLimit); err != nil {
+			return nil, fmt.Errorf("notifier %s: invalid rate_limit: %s", conf.Name, err)
+		}
+	}
+	if conf.BackendErrorAfter != "" {
+		if n.BackendErrorAfter, err = time.ParseDuration(conf.BackendErrorAfter); err != nil {
+			return nil, fmt.Errorf("notifier %s: invalid backend_error_after: %s", conf.Name, err)
+		}
+	}
+	if conf.Template != "" {
+		if n.Template, err = template.New(conf.Name).Funcs(funcs).Parse(conf.Template); err != nil {
+			return nil, fmt.Errorf("notifier %s: invalid template: %s", conf.Name, err)
+		}
+	}
+
+	switch conf.Kind {
+	case "slack":
+		n.Sender = NewSlackSender(conf.URL)
+	case "webhook":
+		n.Sender = NewWebhookSender(conf.URL, conf.Method, conf.Headers)
+	case "email":
+		password := conf.Password
+		if password == "" {
+			password = os.Getenv("SMTP_PASSWORD")
+		}
+		n.Sender = NewEmailSender(conf.SMTPAddress, conf.Username, password, conf.From, conf.To)
+	case "grafana":
+		apiKey := conf.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("GRAFANA_API_KEY")
+		}
+		n.Sender = NewGrafanaSender(conf.URL, apiKey, conf.DashboardUID, conf.PanelID, conf.Tags)
+	default:
+		return nil, fmt.Errorf("notifier %s: unknown kind %s", conf.Name, conf.Kind)
+	}
+	return n, nil
+}
+
+// Notify sends an event unless it isn't routed to the notifier or is rate limited
+func (n *Notifier) Notify(e events.Event) error {
+	if !n.accepts(e) || !n.allow(e) {
+		return nil
+	}
+
+	var text string
+	if n.Template != nil {
+		var b bytes.Buffer
+		if err := n.Template.Execute(&b, e); err != nil {
+			return fmt.Errorf("problem rendering template: %s", err)
+		}
+		text = b.String()
+	}
+	return n.Sender.Send(e, text)
+}
+
+func (n *Notifier) accepts(e events.Event) bool {
+	// evaluations are for metrics and the event stream, they happen every period
+	if e.Type == events.RuleEvaluated {
+		return false
+	}
+	// events without a job, such as global pauses, concern every job
+	if len(n.Jobs) > 0 && e.Job != "" && !n.Jobs[e.Job] {
+		return false
+	}
+	if len(n.Events) > 0 && !n.Events[e.Type] {
+		return false
+	}
+	// a backend failing once is retried on the next period, only report lasting failures
+	if e.Type == events.BackendError {
+		return e.FailingSince != nil && e.Time.Sub(*e.FailingSince) >= n.BackendErrorAfter
+	}
+	return true
+}
+
+// allow records the event and reports whether the rate limit lets it through
+func (n *Notifier) allow(e events.Event) bool {
+	key := e.Type + "/" + e.Job + "/" + e.Group
+	if e.Type == events.BackendError {
+		key = e.Type + "/" + e.Backend
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if last, ok := n.sent[key]; ok && e.Time.Sub(last) < n.RateLimit {
+		log.Debugf("Rate limited %s notification of %s", key, n.Name)
+		return false
+	}
+	n.sent[key] = e.Time
+	return true
+}
+
+// Run sends the events published on the bus to the notifiers until the returned function is called
+func Run(bus *events.Bus, notifiers []*Notifier) func() {
+	ch, stop := bus.Subscribe(100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range ch {
+			for _, n := range notifiers {
+				if err := n.Notify(e); err != nil {
+					log.Errorf("Problem sending %s event to notifier %s: %s", e.Type, n.Name, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		stop()
+		<-done
+	}
+}
@@ -0,0 +1,85 @@
+// Package plugin lets metric backends live in their own binaries.
+//
+// A plugin is an executable named libra-backend-<kind> placed in Libra's plugin
+// directory. Libra starts one process per configured backend of that kind and
+// talks JSON-RPC to it over the process's stdin and stdout, so plugins must log
+// to stderr only. A plugin's main function only has to call Serve:
+//
+//	func main() {
+//		plugin.Serve(&MyBackend{})
+//	}
+package plugin
+
+import (
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+
+	"github.com/underarmour/libra/structs"
+)
+
+// Prefix is the file name prefix of plugin binaries, followed by the backend kind
+const Prefix = "libra-backend-"
+
+// ServiceName is the RPC service a plugin registers
+const ServiceName = "Plugin"
+
+// Backend is implemented by plugins
+type Backend interface {
+	// Configure is called once with the backend stanza before any GetValue call
+	Configure(config structs.Backend) error
+	GetValue(rule structs.Rule) (float64, error)
+}
+
+// Empty is used for RPC calls without a meaningful reply
+type Empty struct{}
+
+// Server exposes a Backend over RPC
+type Server struct {
+	Impl Backend
+}
+
+// Configure passes the backend stanza to the plugin
+func (s *Server) Configure(config structs.Backend, _ *Empty) error {
+	return s.Impl.Configure(config)
+}
+
+// GetValue gets a value from the plugin
+func (s *Server) GetValue(rule structs.Rule, value *float64) error {
+	v, err := s.Impl.GetValue(rule)
+	if err != nil {
+		return err
+	}
+	*value = v
+	return nil
+}
+
+// Serve answers RPC calls from Libra on stdin and stdout until Libra closes stdin
+func Serve(impl Backend) {
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, &Server{Impl: impl}); err != nil {
+		panic(err)
+	}
+	server.ServeCodec(jsonrpc.NewServerCodec(Conn{Reader: os.Stdin, Writer: os.Stdout}))
+}
+
+// Conn joins the two halves of a stdio pipe into one connection
+type Conn struct {
+	io.Reader
+	io.Writer
+}
+
+// Close closes whichever halves can be closed
+func (c Conn) Close() error {
+	var err error
+	if closer, ok := c.Writer.(io.Closer); ok {
+		err = closer.Close()
+	}
+	if closer, ok := c.Reader.(io.Closer); ok {
+		if rerr := closer.Close(); err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
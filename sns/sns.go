@@ -0,0 +1,207 @@
+package sns
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types sent in the Type field and the x-amz-sns-message-type header
+const (
+	TypeNotification             = "Notification"
+	TypeSubscriptionConfirmation = "SubscriptionConfirmation"
+	TypeUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+)
+
+// hostPattern matches the hosts SNS serves signing certificates and subscription URLs from
+var hostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// Message is an SNS HTTP(S) message: https://docs.aws.amazon.com/sns/latest/dg/sns-message-and-json-formats.html
+type Message struct {
+	Type             string
+	MessageId        string
+	Token            string
+	TopicArn         string
+	Subject          string
+	Message          string
+	Timestamp        string
+	SignatureVersion string
+	Signature        string
+	SigningCertURL   string
+	SubscribeURL     string
+	UnsubscribeURL   string
+}
+
+// stringToSign builds the canonical form of the message that SNS signs
+func (m *Message) stringToSign() (string, error) {
+	var keys []string
+	switch m.Type {
+	case TypeNotification:
+		keys = []string{"Message", "MessageId", "Subject", "Timestamp", "TopicArn", "Type"}
+	case TypeSubscriptionConfirmation, TypeUnsubscribeConfirmation:
+		keys = []string{"Message", "MessageId", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"}
+	default:
+		return "", fmt.Errorf("unknown message type %q", m.Type)
+	}
+
+	values := map[string]string{
+		"Message":      m.Message,
+		"MessageId":    m.MessageId,
+		"Subject":      m.Subject,
+		"SubscribeURL": m.SubscribeURL,
+		"Timestamp":    m.Timestamp,
+		"Token":        m.Token,
+		"TopicArn":     m.TopicArn,
+		"Type":         m.Type,
+	}
+	var b bytes.Buffer
+	for _, k := range keys {
+		// Subject is only signed when the notification has one
+		if k == "Subject" && m.Subject == "" {
+			continue
+		}
+		b.WriteString(k + "\n" + values[k] + "\n")
+	}
+	return b.String(), nil
+}
+
+// Verifier checks message signatures, caching signing certificates by URL
+type Verifier struct {
+	HTTP *http.Client
+
+	lock  sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+// NewVerifier creates a Verifier, including a custom net/http client
+func NewVerifier() *Verifier {
+	return &Verifier{
+		HTTP: &http.Client{
+			Timeout: time.Second * 10,
+		},
+		certs: map[string]*x509.Certificate{},
+	}
+}
+
+// Verify checks that the message was signed by SNS
+func (v *Verifier) Verify(m *Message) error {
+	var hashType crypto.Hash
+	var h hash.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hashType, h = crypto.SHA1, sha1.New()
+	case "2":
+		hashType, h = crypto.SHA256, sha256.New()
+	default:
+		return fmt.Errorf("unsupported signature version %q", m.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+	s, err := m.stringToSign()
+	if err != nil {
+		return err
+	}
+	cert, err := v.certificate(m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("signing certificate does not hold an RSA key")
+	}
+
+	h.Write([]byte(s))
+	if err := rsa.VerifyPKCS1v15(key, hashType, h.Sum(nil), signature); err != nil {
+		return errors.New("signature does not match the message")
+	}
+	return nil
+}
+
+// ConfirmSubscription visits the SubscribeURL of a subscription confirmation
+func (v *Verifier) ConfirmSubscription(m *Message) error {
+	if m.Type != TypeSubscriptionConfirmation {
+		return fmt.Errorf("%s is not a subscription confirmation", m.Type)
+	}
+	if err := checkURL(m.SubscribeURL); err != nil {
+		return err
+	}
+
+	resp, err := v.HTTP.Get(m.SubscribeURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("confirming subscription returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (v *Verifier) certificate(certURL string) (*x509.Certificate, error) {
+	v.lock.Lock()
+	cert, ok := v.certs[certURL]
+	v.lock.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	if err := checkURL(certURL); err != nil {
+		return nil, err
+	}
+	resp, err := v.HTTP.Get(certURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching signing certificate returned %s", resp.Status)
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("signing certificate is not PEM encoded")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing signing certificate: %s", err)
+	}
+
+	v.lock.Lock()
+	v.certs[certURL] = cert
+	v.lock.Unlock()
+	return cert, nil
+}
+
+// checkURL only allows HTTPS URLs on SNS hosts, so that messages cannot make Libra fetch arbitrary URLs
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || !hostPattern.MatchString(u.Hostname()) {
+		return fmt.Errorf("%s is not an SNS URL", raw)
+	}
+	return nil
+}
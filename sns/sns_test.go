@@ -0,0 +1,146 @@
+package sns
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// signingCert is the self-signed certificate whose key signed the fixtures
+const signingCert = `-----BEGIN CERTIFICATE-----
+MIICFjCCAX+gAwIBAgIUOimaDoD8qG2P/2lIlFDT2FGwSM4wDQYJKoZIhvcNAQEL
+BQAwHDEaMBgGA1UEAwwRc25zLmFtYXpvbmF3cy5jb20wIBcNMjYxMDE5MDgxNTAw
+WhgPMjEyNjA5MjUwODE1MDBaMBwxGjAYBgNVBAMMEXNucy5hbWF6b25hd3MuY29t
+MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDWbRrtZ/1AjWxtTwCwboJNne3L
+6NUDHT2B/39nhy8hTdmhFlfuSqxe5RmtpJuhYJDZ0cohL+JeHwgr151YvTRNgQ6F
+SjYXSPegbJfQZPnqnJRS/uSnL7RELIwPToWQDg587nhf7zkHMion7xEe7gDAtVcb
+XpZs+VQSzp6WFCJ++QIDAQABo1MwUTAdBgNVHQ4EFgQUdfbyF4ZHjPP1MEiNILdO
+yDnXkfgwHwYDVR0jBBgwFoAUdfbyF4ZHjPP1MEiNILdOyDnXkfgwDwYDVR0TAQH/
+BAUwAwEB/zANBgkqhkiG9w0BAQsFAAOBgQBHH7FE9zsE/vN9NtuoAgng7Hopvf7y
+tfcGyzdNHUA3VTGRHn10B3AXqIBm1veChFLAYQGVjK7AzDu2XzrW36+b643IyzVi
+guM6YRCe8Ixv4DV4YNlFG+P+NgBebzsSQex5iR3cClQpOxoN9RZoDpGaNQKS8aVt
+Vh9tl/CFPzywCA==
+-----END CERTIFICATE-----`
+
+const certURL = "https://sns.us-west-2.amazonaws.com/SimpleNotificationService-test.pem"
+
+// notification returns a notification signed with version 1 (SHA1)
+func notification() *Message {
+	return &Message{
+		Type:             TypeNotification,
+		MessageId:        "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+		TopicArn:         "arn:aws:sns:us-west-2:123456789012:libra",
+		Subject:          "Scale",
+		Message:          `{"job":"web","group":"app","count":4}`,
+		Timestamp:        "2021-06-01T10:00:00.000Z",
+		SignatureVersion: "1",
+		Signature:        "OftzQRS6AiRQwY3v9c06Qicf2q/RULVB3eTdU1pND+igi0E7H0POsVC/R+B29uykLcVX44FYCByO9PGEFLutJj7ON06KqngaTogfcvR82yhxxh20TZIwhB7Z+5iZB5zOYgI6dbHR03NhUOGz8ww+NBR0QnSrhCqBwDKu+byvTts=",
+		SigningCertURL:   certURL,
+	}
+}
+
+// the same notification signed with version 2 (SHA256)
+const signatureV2 = "Yp7oTINc6rlBK/2vAKmz43H3FYE6m+/OGr3FcmmFAIgdlAwxADEMTkifSde6gYVQpAXe2MT/d035LK+f4T0ykYHioFdiK0ZsNj/XNQilDwcAgbxqJLFTp+iW99q5thbUoHx3Ju9WTHkKnzQWveI54gu86YkEIM7MxupWONdJstA="
+
+// certServer serves the signing certificate and counts the requests for it
+type certServer struct {
+	requests int
+}
+
+func (s *certServer) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.requests++
+	status := http.StatusOK
+	if r.URL.String() != certURL {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(strings.NewReader(signingCert)),
+		Request:    r,
+	}, nil
+}
+
+func verifier() (*Verifier, *certServer) {
+	server := &certServer{}
+	v := NewVerifier()
+	v.HTTP = &http.Client{Transport: server}
+	return v, server
+}
+
+func TestStringToSign(t *testing.T) {
+	m := notification()
+	s, err := m.stringToSign()
+	expected := "Message\n{\"job\":\"web\",\"group\":\"app\",\"count\":4}\nMessageId\n22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324\n" +
+		"Subject\nScale\nTimestamp\n2021-06-01T10:00:00.000Z\nTopicArn\narn:aws:sns:us-west-2:123456789012:libra\nType\nNotification\n"
+	if err != nil || s != expected {
+		t.Errorf("expected %q, got %q and %v", expected, s, err)
+	}
+
+	m.Subject = ""
+	s, _ = m.stringToSign()
+	if strings.Contains(s, "Subject") {
+		t.Errorf("expected no subject, got %q", s)
+	}
+
+	confirmation := &Message{
+		Type:         TypeSubscriptionConfirmation,
+		MessageId:    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+		Token:        "2336412f37",
+		TopicArn:     "arn:aws:sns:us-west-2:123456789012:libra",
+		Message:      "You have chosen to subscribe to the topic",
+		SubscribeURL: "https://sns.us-west-2.amazonaws.com/?Action=ConfirmSubscription",
+		Timestamp:    "2021-06-01T10:00:00.000Z",
+	}
+	s, _ = confirmation.stringToSign()
+	expected = "Message\nYou have chosen to subscribe to the topic\nMessageId\n165545c9-2a5c-472c-8df2-7ff2be2b3b1b\n" +
+		"SubscribeURL\nhttps://sns.us-west-2.amazonaws.com/?Action=ConfirmSubscription\nTimestamp\n2021-06-01T10:00:00.000Z\n" +
+		"Token\n2336412f37\nTopicArn\narn:aws:sns:us-west-2:123456789012:libra\nType\nSubscriptionConfirmation\n"
+	if s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	if _, err := (&Message{Type: "Other"}).stringToSign(); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	v, server := verifier()
+
+	if err := v.Verify(notification()); err != nil {
+		t.Errorf("expected a valid version 1 signature, got %s", err)
+	}
+	m := notification()
+	m.SignatureVersion, m.Signature = "2", signatureV2
+	if err := v.Verify(m); err != nil {
+		t.Errorf("expected a valid version 2 signature, got %s", err)
+	}
+	if server.requests != 1 {
+		t.Errorf("expected the certificate to be fetched once, got %d requests", server.requests)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	cases := map[string]func(m *Message){
+		"signature does not match the message":                   func(m *Message) { m.Message = `{"job":"web","group":"app","count":40}` },
+		`unsupported signature version "3"`:                      func(m *Message) { m.SignatureVersion = "3" },
+		"invalid signature: illegal base64 data at input byte 3": func(m *Message) { m.Signature = "not base64" },
+		"https://example.com/cert.pem is not an SNS URL":         func(m *Message) { m.SigningCertURL = "https://example.com/cert.pem" },
+		"http://sns.us-west-2.amazonaws.com/cert.pem is not an SNS URL": func(m *Message) {
+			m.SigningCertURL = "http://sns.us-west-2.amazonaws.com/cert.pem"
+		},
+		"fetching signing certificate returned Not Found": func(m *Message) {
+			m.SigningCertURL = "https://sns.us-east-1.amazonaws.com/missing.pem"
+		},
+	}
+	for expected, tamper := range cases {
+		v, _ := verifier()
+		m := notification()
+		tamper(m)
+		if err := v.Verify(m); err == nil || err.Error() != expected {
+			t.Errorf("expected %q, got %v", expected, err)
+		}
+	}
+}
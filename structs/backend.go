@@ -1,18 +1,95 @@
 package structs
 
+import "time"
+
 // Backender interface
 type Backender interface {
 	Info() *Backend
 	GetValue(rule Rule) (float64, error)
 }
 
+// Checker is implemented by backends that can verify their connectivity
+// without running a rule
+type Checker interface {
+	Check() error
+}
+
+// Sampler is implemented by backends that can return the datapoints they
+// reduce to the value of a rule
+type Sampler interface {
+	Sample(rule Rule) (float64, []Series, error)
+}
+
+// Series is a named sequence of datapoints read by a backend, Values are nil
+// where the backend has no data
+type Series struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
+}
+
+// Publisher is implemented by backends that can store metrics about the
+// decisions Libra makes, under a path or namespace starting with prefix
+type Publisher interface {
+	Publish(prefix string, points []Point) error
+}
+
+// Point is a metric about a rule of a task group
+type Point struct {
+	Name  string
+	Value float64
+	Time  time.Time
+	Job   string
+	Group string
+	Rule  string
+}
+
 // Backend struct
 type Backend struct {
 	Name   string `mapstructure:"name"`
 	Kind   string `mapstructure:"kind"`
 	Region string `mapstructure:"region"`
+	// CacheTTL shares results between rules issuing the same query, e.g. "30s"
+	CacheTTL string `mapstructure:"cache_ttl" hcl:"cache_ttl"`
+	// AWS-specific
+	Profile    string `mapstructure:"profile"`
+	RoleARN    string `mapstructure:"role_arn" hcl:"role_arn"`
+	ExternalID string `mapstructure:"external_id" hcl:"external_id"`
+	Endpoint   string `mapstructure:"endpoint"`
+	// Nomad, Consul and Redis, nomad backends default to NOMAD_ADDRESS, then the nomad stanza
+	Address string `mapstructure:"address"`
+	// Consul-specific, also uses address and token
+	Datacenter string `mapstructure:"datacenter"`
 	// Graphite-specific
-	Host     string `mapstructure:"host"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
+	Host       string `mapstructure:"host"`
+	Username   string `mapstructure:"username"`
+	Password   string `mapstructure:"password"`
+	PathPrefix string `mapstructure:"path_prefix" hcl:"path_prefix"`
+	// CarbonAddress receives published metrics, defaults to port 2003 of host
+	CarbonAddress string `mapstructure:"carbon_address" hcl:"carbon_address"`
+	// InfluxDB-specific, also uses host, username and password
+	Token         string `mapstructure:"token"`
+	QueryLanguage string `mapstructure:"query_language" hcl:"query_language"`
+	Database      string `mapstructure:"database"`
+	Org           string `mapstructure:"org"`
+	Bucket        string `mapstructure:"bucket"`
+	// HTTP-specific, also uses username, password and token
+	URL     string            `mapstructure:"url"`
+	Method  string            `mapstructure:"method"`
+	Headers map[string]string `mapstructure:"headers"`
+	Body    string            `mapstructure:"body"`
+	// RabbitMQ-specific, also uses host, username and password
+	VHost string `mapstructure:"vhost"`
+	// Elasticsearch-specific, also uses host, username, password and token
+	Index          string `mapstructure:"index"`
+	TimestampField string `mapstructure:"timestamp_field" hcl:"timestamp_field"`
+	// Kafka-specific, also uses timeout
+	Brokers []string `mapstructure:"brokers"`
+	// Datadog and New Relic
+	APIBase   string `mapstructure:"api_base" hcl:"api_base"`
+	APIKey    string `mapstructure:"api_key" hcl:"api_key"`
+	AppKey    string `mapstructure:"app_key" hcl:"app_key"`
+	AccountID int    `mapstructure:"account_id" hcl:"account_id"`
+	// Exec-specific
+	Command []string `mapstructure:"command"`
+	Timeout string   `mapstructure:"timeout"`
 }
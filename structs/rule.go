@@ -3,15 +3,41 @@ package structs
 // Rule struct
 type Rule struct {
 	Name            string
-	Backend         string `hcl:"backend"`
-	BackendInstance Backender
-	Comparison      string  `hcl:"comparison"`
-	ComparisonValue float64 `hcl:"comparison_value,float"`
-	Action          string  `hcl:"action"`
-	ActionValue     int     `hcl:"action_value,int"`
-	MetricName      string  `hcl:"metric_name"`
-	MetricNamespace string  `hcl:"metric_namespace"`
-	DimensionName   string  `hcl:"dimension_name"`
-	DimensionValue  string  `hcl:"dimension_value"`
-	Period          string  `hcl:"cron"`
+	Backend         string    `hcl:"backend"`
+	BackendInstance Backender `json:"-"`
+	Comparison      string    `hcl:"comparison"`
+	ComparisonValue float64   `hcl:"comparison_value,float"`
+	Action          string    `hcl:"action"`
+	ActionValue     int       `hcl:"action_value,int"`
+	MetricName      string    `hcl:"metric_name"`
+	MetricNamespace string    `hcl:"metric_namespace"`
+	DimensionName   string    `hcl:"dimension_name"`
+	DimensionValue  string    `hcl:"dimension_value"`
+	Period          string    `hcl:"cron"`
+	// Nomad-specific, default to the job and group the rule is defined in
+	Job   string `hcl:"job"`
+	Group string `hcl:"group"`
+	// Graphite-specific, from is also the time range of Elasticsearch queries
+	From          string `hcl:"from"`
+	Until         string `hcl:"until"`
+	MaxDataPoints int    `hcl:"max_data_points"`
+	// Query is a backend-specific query, e.g. InfluxQL or Flux
+	Query string `hcl:"query"`
+	// Expression is a JMESPath expression extracting the value from a JSON response
+	Expression string `hcl:"expression"`
+	// Consul-specific, the service (and optional tag) to count instances of, or a KV key
+	// to read. Key is also the Redis key to measure.
+	Service string `hcl:"service"`
+	Tag     string `hcl:"tag"`
+	Key     string `hcl:"key"`
+	// Queue is an SQS queue name or URL, or a RabbitMQ queue name
+	Queue string `hcl:"queue"`
+	// Kafka-specific
+	Topic         string `hcl:"topic"`
+	ConsumerGroup string `hcl:"consumer_group"`
+	// Elasticsearch-specific, index defaults to the backend's
+	Index string `hcl:"index"`
+	Field string `hcl:"field"`
+	// Aggregation reduces several series into one value: avg (default), sum, max or min
+	Aggregation string `hcl:"aggregation"`
 }